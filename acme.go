@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	challengeHTTP    = "http-01"
+	challengeTLSALPN = "tls-alpn-01"
+)
+
+// newACMEManager returns the manager requesting certificates for domains
+// from the acme directory, Let's Encrypt if it is empty. The directory's
+// own certificate is verified against caFile when given, e.g. for a local
+// Pebble instance.
+func newACMEManager(certDir string, domains []string, directory, email, caFile string) (*autocert.Manager, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("at least one domain is required to request certificates")
+	}
+
+	m := &autocert.Manager{
+		Cache:      autocert.DirCache(certDir),
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Email:      email,
+	}
+
+	if directory != "" || caFile != "" {
+		m.Client = &acme.Client{DirectoryURL: directory}
+	}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		m.Client.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+	return m, nil
+}
+
+// alpnChallengeConfig answers tls-alpn-01 challenges on the server's own
+// listener. The gRPC credentials replace NextProtos with h2, so challenge
+// handshakes are given a config of their own.
+func alpnChallengeConfig(m *autocert.Manager) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if len(hello.SupportedProtos) != 1 || hello.SupportedProtos[0] != acme.ALPNProto {
+			return nil, nil
+		}
+		return &tls.Config{
+			GetCertificate: m.GetCertificate,
+			NextProtos:     []string{acme.ALPNProto},
+		}, nil
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/server"
+	"github.com/danielcopaciu/chat/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const adminTimeout = 5 * time.Second
+
+// adminSettings controls the admin service of the server.
+type adminSettings struct {
+	address  string
+	token    string
+	logLevel *slog.LevelVar
+}
+
+// startAdminServer serves the admin service on its own listener, so that it
+// can be kept off the network clients reach.
+func startAdminServer(address string, creds credentials.TransportCredentials, admin *server.Admin) (func(), error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(telemetry.UnaryServerInterceptor, admin.Authorize),
+	)
+	chat.RegisterAdminServer(grpcServer, admin)
+
+	slog.Info("Starting admin server", "address", address)
+	go grpcServer.Serve(lis)
+
+	return func() {
+		slog.Info("Stopping admin server")
+		grpcServer.Stop()
+	}, nil
+}
+
+// adminTarget is the admin service the admin commands call.
+type adminTarget struct {
+	address    string
+	token      string
+	insecure   bool
+	clientCert string
+	clientKey  string
+	caFile     string
+	serverName string
+}
+
+// runAdmin connects to the admin service of target and hands it to call.
+func runAdmin(target adminTarget, call func(context.Context, *client.AdminClient) error) error {
+	tlsConfig, _, err := clientTLSConfig(target.clientCert, target.clientKey, target.caFile, target.serverName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	defer cancel()
+
+	admin, err := client.DialAdmin(ctx, target.address, target.insecure, tlsConfig, target.token)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	return call(ctx, admin)
+}
+
+func adminSessions(ctx context.Context, admin *client.AdminClient) error {
+	resp, err := admin.ListSessions(ctx, &chat.ListSessionsRequest{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USERNAME\tSESSION\tKIND\tPEER\tCONNECTED\tSUITE\tROOMS\tQUEUE\tJOINED")
+	for _, s := range resp.Sessions {
+		connected := time.Since(time.Unix(s.ConnectedAt, 0)).Round(time.Second)
+		rooms := make([]string, 0, len(s.Rooms))
+		for _, room := range s.Rooms {
+			if room == server.PublicRoom {
+				room = "(public)"
+			}
+			rooms = append(rooms, room)
+		}
+		suite := s.CipherSuite
+		if suite == "" {
+			suite = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s ago\t%s\t%s\t%d\t%t\n",
+			s.Username, s.Id, s.Kind, s.Peer, connected, suite, strings.Join(rooms, ","), s.QueueDepth, s.Joined)
+	}
+	return w.Flush()
+}
+
+func adminDisconnect(ctx context.Context, admin *client.AdminClient, username, reason string) error {
+	if _, err := admin.Disconnect(ctx, &chat.DisconnectRequest{Username: username, Reason: reason}); err != nil {
+		return err
+	}
+	fmt.Printf("Disconnected %s\n", username)
+	return nil
+}
+
+func adminAnnounce(ctx context.Context, admin *client.AdminClient, room, text string) error {
+	_, err := admin.Announce(ctx, &chat.AnnounceRequest{Room: room, Text: text})
+	return err
+}
+
+func adminStats(ctx context.Context, admin *client.AdminClient) error {
+	stats, err := admin.Stats(ctx, &chat.StatsRequest{})
+	if err != nil {
+		return err
+	}
+
+	started := time.Unix(stats.StartedAt, 0)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Started:\t%s (up %s)\n", started.Format(time.RFC3339), time.Since(started).Round(time.Second))
+	fmt.Fprintf(w, "Sessions:\t%d clients, %d gateway users\n", stats.Clients, stats.Gateways)
+	fmt.Fprintf(w, "Stream subscribers:\t%d\n", stats.Subscribers)
+	fmt.Fprintf(w, "Broadcast backlog:\t%d of %d\n", stats.BroadcastBacklog, stats.BroadcastCapacity)
+	fmt.Fprintf(w, "Logins:\t%d (%d failed)\n", stats.Logins, stats.FailedLogins)
+	fmt.Fprintf(w, "Messages:\t%d received, %d sent\n", stats.MessagesReceived, stats.MessagesSent)
+	return w.Flush()
+}
+
+// adminUpdateSettings shows the runtime settings of the server, after
+// changing them to update if it is set.
+func adminUpdateSettings(ctx context.Context, admin *client.AdminClient, update *chat.Settings) error {
+	var settings *chat.Settings
+	var err error
+	if update != nil {
+		settings, err = admin.UpdateSettings(ctx, &chat.UpdateSettingsRequest{Settings: update})
+	} else {
+		settings, err = admin.GetSettings(ctx, &chat.GetSettingsRequest{})
+	}
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Cipher suites:\t%s\n", strings.Join(settings.CipherSuites, ", "))
+	fmt.Fprintf(w, "Log level:\t%s\n", settings.LogLevel)
+	fmt.Fprintf(w, "Max upload size:\t%d bytes\n", settings.MaxUploadSize)
+	return w.Flush()
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"google.golang.org/grpc"
+)
+
+// AdminClient calls the admin service of a server with the admin token.
+type AdminClient struct {
+	chat.AdminClient
+	conn *grpc.ClientConn
+}
+
+// DialAdmin connects to the admin service at address.
+func DialAdmin(ctx context.Context, address string, insecure bool, tlsConfig *tls.Config, token string) (*AdminClient, error) {
+	conn, err := dial(ctx, address, insecure, tlsConfig, grpc.WithPerRPCCredentials(adminToken(token)))
+	if err != nil {
+		return nil, err
+	}
+	return &AdminClient{AdminClient: chat.NewAdminClient(conn), conn: conn}, nil
+}
+
+func (a *AdminClient) Close() error {
+	return a.conn.Close()
+}
+
+// adminToken sends the admin token as a bearer token. It is sent over
+// plaintext connections too, as the server may run without TLS.
+type adminToken string
+
+func (t adminToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t adminToken) RequireTransportSecurity() bool {
+	return false
+}
@@ -2,10 +2,11 @@ package client
 
 import (
 	"bufio"
+	"bytes"
 	"context"
-	"crypto/rand"
+	"crypto/cipher"
+	"crypto/ed25519"
 	"crypto/rsa"
-	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -13,48 +14,129 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 
 	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/identity"
+	"github.com/danielcopaciu/chat/secure"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-var hash = sha256.New()
-
 type Client struct {
 	username        string
 	serverAddress   string
 	insecure        bool
+	conn            *grpc.ClientConn
 	chatClient      chat.ChatClient
+	stream          chat.Chat_JoinClient
 	privateKey      *rsa.PrivateKey
 	publicServerKey *rsa.PublicKey
+	sessionKey      cipher.AEAD
+	suites          []secure.Suite
+	ratchets        *ratchets
+	signingKey      ed25519.PrivateKey
+	// selfKey wraps the message keys of our own messages, which come back
+	// to us with the rest of the conversation.
+	selfKey cipher.AEAD
+
+	// stamper and replays stamp and check the envelopes on the stream when
+	// the server speaks protocol version 2.
+	stamper *secure.Stamper
+	replays *secure.ReplayGuard
+	// sendMtx keeps envelopes in the order of their stamps.
+	sendMtx sync.Mutex
+
+	// directory remembers the keys of every user seen in the key directory
+	// to verify the signatures of their messages.
+	directory    map[string]*chat.PublicKey
+	directoryMtx sync.Mutex
+
+	knownServers  *KnownServers
+	verifiedUsers *VerifiedUsers
+	tlsConfig     *tls.Config
+
+	// pending holds the fingerprints safety numbers were last shown for,
+	// until the user confirms them.
+	pending map[string]string
+}
+
+// recipient is a user a message is encrypted for: through a ratchet session
+// when they have a ratchet key, otherwise with their RSA key.
+type recipient struct {
+	publicKey  *rsa.PublicKey
+	ratchetKey []byte
 }
 
-func NewClient(username, serverAddress string, insecure bool) (*Client, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+// NewClient creates a client for username, known to others by id. A client
+// with a nil id gets a fresh identity that only lasts as long as it does.
+func NewClient(username, serverAddress string, insecure bool, id *identity.Identity) (*Client, error) {
+	if id == nil {
+		var err error
+		if id, err = identity.Generate(); err != nil {
+			return nil, err
+		}
+	}
+
+	ratchets, err := newRatchets()
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to generate key")
+		return nil, err
+	}
+
+	key, err := secure.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	selfKey, err := secure.NewAEAD(key)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Client{
 		username:      username,
 		serverAddress: serverAddress,
 		insecure:      insecure,
-		privateKey:    privateKey,
+		privateKey:    id.EncryptionKey,
+		ratchets:      ratchets,
+		signingKey:    id.SigningKey,
+		selfKey:       selfKey,
+		suites:        secure.Suites(),
+		directory:     make(map[string]*chat.PublicKey),
+		pending:       make(map[string]string),
 	}, nil
 }
 
+// Username returns the name the client logs in with.
+func (c *Client) Username() string {
+	return c.username
+}
+
+// SetTLSConfig sets the TLS configuration to connect with when the client is
+// not insecure, e.g. to present a client certificate.
+func (c *Client) SetTLSConfig(config *tls.Config) {
+	c.tlsConfig = config
+}
+
+// PinServerKeys makes the client check the server key against the keys
+// known for each server.
+func (c *Client) PinServerKeys(knownServers *KnownServers) {
+	c.knownServers = knownServers
+}
+
 func (c *Client) Login(ctx context.Context) error {
 	loginCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	fmt.Printf("%T", c.privateKey.PublicKey)
 	publicKey, err := x509.MarshalPKIXPublicKey(&c.privateKey.PublicKey)
 	if err != nil {
 		return errors.WithMessage(err, "failed to generate client key")
@@ -65,7 +147,25 @@ func (c *Client) Login(ctx context.Context) error {
 		Bytes: publicKey,
 	})
 
-	loginResponse, err := c.chatClient.Login(loginCtx, &chat.LoginRequest{Username: c.username, ClientKey: pubBytes})
+	offer, err := newOffer(c.suites)
+	if err != nil {
+		return err
+	}
+
+	ratchetKeySignature, err := secure.Sign(c.privateKey, c.ratchets.key.Public)
+	if err != nil {
+		return err
+	}
+
+	loginResponse, err := c.chatClient.Login(loginCtx, &chat.LoginRequest{
+		Username:            c.username,
+		ClientKey:           pubBytes,
+		RatchetKey:          c.ratchets.key.Public,
+		RatchetKeySignature: ratchetKeySignature,
+		SigningKey:          c.signingKey.Public().(ed25519.PublicKey),
+		ProtocolVersion:     secure.ProtocolVersion,
+		KeyShares:           offer.shares,
+	})
 	if err != nil {
 		return err
 	}
@@ -86,54 +186,139 @@ func (c *Client) Login(ctx context.Context) error {
 	default:
 		return errors.New("server key has an invalid type")
 	}
+
+	if err := c.checkServerKey(c.publicServerKey, loginResponse.KeyRotations); err != nil {
+		return err
+	}
+
+	c.sessionKey, err = offer.accept(c.publicServerKey, loginResponse)
+	if err != nil {
+		return err
+	}
+
+	c.stamper, c.replays = nil, nil
+	if loginResponse.ProtocolVersion >= secure.StampVersion {
+		c.stamper = secure.NewStamper(c.sessionKey, secure.ClientToServer)
+		c.replays = secure.NewReplayGuard(c.sessionKey, secure.ServerToClient)
+	}
 	return nil
 }
 
-func (c *Client) Run(ctx context.Context) error {
+// Connect dials the server, logs in and joins the conversation. The
+// conversation stays open until ctx is cancelled or Close is called.
+func (c *Client) Connect(ctx context.Context) error {
+	conn, err := dial(ctx, c.serverAddress, c.insecure, c.tlsConfig)
+	if err != nil {
+		return err
+	}
+	log.Printf("Succesfully connected to server on %s\n", c.serverAddress)
+
+	c.conn = conn
+	c.chatClient = chat.NewChatClient(conn)
+	if err := c.Login(ctx); err != nil {
+		conn.Close()
+		return err
+	}
+
+	stream, err := c.chatClient.Join(c.outgoingContext(ctx))
+	if err != nil {
+		conn.Close()
+		return errors.WithMessage(err, "unable to join conversation")
+	}
+	c.stream = stream
+	return nil
+}
+
+func dial(ctx context.Context, serverAddress string, insecure bool, tlsConfig *tls.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	connCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
 	var creds grpc.DialOption
-	if c.insecure {
+	if insecure {
 		creds = grpc.WithInsecure()
 	} else {
-		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
 	}
-	conn, err := grpc.DialContext(connCtx, c.serverAddress,
+	conn, err := grpc.DialContext(connCtx, serverAddress, append([]grpc.DialOption{
 		creds,
-		grpc.WithWaitForHandshake(),
 		grpc.WithBlock(),
-	)
+	}, opts...)...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to connect")
+	}
+	return conn, nil
+}
+
+// FetchServerKey asks the server at serverAddress for its current key
+// without logging in.
+func FetchServerKey(ctx context.Context, serverAddress string, insecure bool, tlsConfig *tls.Config) (*rsa.PublicKey, error) {
+	conn, err := dial(ctx, serverAddress, insecure, tlsConfig)
 	if err != nil {
-		return errors.WithMessage(err, "unable to connect")
+		return nil, err
 	}
 	defer conn.Close()
-	log.Printf("Succesfully connected to server on %s\n", c.serverAddress)
 
-	c.chatClient = chat.NewChatClient(conn)
-	if err := c.Login(ctx); err != nil {
+	resp, err := chat.NewChatClient(conn).ServerKey(ctx, &chat.ServerKeyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return secure.ParsePublicKey(resp.Key)
+}
+
+// checkServerKey verifies the key offered by the server against the key
+// pinned for its address, following the rotations the server presented.
+func (c *Client) checkServerKey(serverKey *rsa.PublicKey, rotations []*chat.KeyRotation) error {
+	if c.knownServers == nil {
+		return nil
+	}
+
+	fingerprint, err := secure.KeyFingerprint(serverKey)
+	if err != nil {
 		return err
 	}
+	return c.knownServers.Check(c.serverAddress, fingerprint, rotations)
+}
 
+// outgoingContext attaches the username the server identifies the session by.
+func (c *Client) outgoingContext(ctx context.Context) context.Context {
 	md := metadata.New(map[string]string{"username": c.username})
-	clientContext := metadata.NewOutgoingContext(ctx, md)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// Close ends the conversation and releases the connection to the server.
+func (c *Client) Close() error {
+	if c.stream != nil {
+		c.stream.CloseSend()
+	}
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
 
-	clientContext, cancel = context.WithCancel(clientContext)
+func (c *Client) Run(ctx context.Context) error {
+	clientContext, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	stream, err := c.chatClient.Join(clientContext)
-	defer stream.CloseSend()
+	if err := c.Connect(clientContext); err != nil {
+		return err
+	}
+	defer c.Close()
 
 	sendErrs := make(chan error)
 	go func() {
 		defer close(sendErrs)
-		sendErrs <- c.send(stream)
+		sendErrs <- c.send()
 	}()
 
 	receiveErrs := make(chan error)
 	go func() {
 		defer close(receiveErrs)
-		receiveErrs <- c.receive(stream)
+		receiveErrs <- c.receive()
 	}()
 
 	select {
@@ -157,49 +342,310 @@ func (c *Client) Logout() error {
 	return err
 }
 
-func (c *Client) getEnvelope(msg chat.Message) (*chat.Envelope, error) {
+func (c *Client) getEnvelope(msg chat.Message, recipients map[string]recipient) (*chat.Envelope, error) {
+	msg.Signature = secure.SignMessage(c.signingKey, []byte(msg.Sender), nil, []byte(msg.Value))
+
 	data, err := proto.Marshal(&msg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to send message")
 	}
 
-	encrypted, err := rsa.EncryptOAEP(hash, rand.Reader, c.publicServerKey, data, nil)
+	key, encrypted, err := secure.SealMessage(data, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to send message")
 	}
 
-	return &chat.Envelope{Message: encrypted}, nil
+	self, err := secure.Seal(c.selfKey, key, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send message")
+	}
+
+	keys := map[string]*chat.WrappedKey{c.username: {Key: self}}
+	for username, r := range recipients {
+		if r.ratchetKey == nil {
+			wrapped, err := secure.WrapKey(r.publicKey, key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to send message")
+			}
+			keys[username] = &chat.WrappedKey{Key: wrapped}
+			continue
+		}
+
+		wrapped, err := c.ratchets.wrap(username, r.ratchetKey, key, keyAssociatedData(c.username, username))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send message")
+		}
+		keys[username] = wrapped
+	}
+
+	return &chat.Envelope{Message: encrypted, Keys: keys}, nil
 }
 
-func (c *Client) send(stream chat.Chat_JoinClient) error {
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		value := scanner.Text()
-		switch value {
-		case "/logout":
-			return c.Logout()
-		default:
-			message := chat.Message{
-				Sender: c.username,
-				Value:  scanner.Text(),
+// keyAssociatedData binds a wrapped message key to its sender and recipient.
+func keyAssociatedData(sender, recipient string) []byte {
+	return []byte(sender + "\x00" + recipient)
+}
+
+// Keys fetches the public keys of the users currently logged in from the
+// server's key directory.
+func (c *Client) Keys(ctx context.Context) ([]*chat.PublicKey, error) {
+	resp, err := c.chatClient.Keys(c.outgoingContext(ctx), &chat.KeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []*chat.PublicKey
+	c.directoryMtx.Lock()
+	for _, key := range resp.Keys {
+		if previous := c.directory[key.Username]; previous == nil || !bytes.Equal(previous.Key, key.Key) || !bytes.Equal(previous.SigningKey, key.SigningKey) {
+			changed = append(changed, key)
+		}
+		c.directory[key.Username] = key
+	}
+	c.directoryMtx.Unlock()
+
+	for _, key := range changed {
+		if c.checkVerified(key) == changedKey {
+			log.Printf("WARNING: the keys of %s have changed since you verified them. Compare your safety numbers again with /verify %s", key.Username, key.Username)
+		}
+	}
+	return resp.Keys, nil
+}
+
+// verifySender checks the signature of a message sender sent to room
+// against their signing key, refreshing the keys from the directory once
+// when it does not match. The messages of gateway users are signed by the
+// server, and checked against the server key pinned at login rather than
+// the key of their directory entry. It returns the note to flag the sender
+// with, if any.
+func (c *Client) verifySender(sender, room string, msg *chat.Message) string {
+	for refreshed := false; ; refreshed = true {
+		c.directoryMtx.Lock()
+		key := c.directory[sender]
+		c.directoryMtx.Unlock()
+
+		switch {
+		case key != nil && key.Gateway:
+			if secure.Verify(c.publicServerKey, msg.Signature, []byte(msg.Sender), []byte(room), []byte(msg.Value)) != nil {
+				return " (unverified, via gateway)"
+			}
+			return " (via gateway)"
+		case key != nil && secure.VerifyMessage(key.SigningKey, msg.Signature, []byte(msg.Sender), []byte(room), []byte(msg.Value)) == nil:
+			return c.verificationNote(key)
+		case refreshed:
+			return " (unverified)"
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		_, err := c.Keys(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to fetch the key of %s: %v", sender, err)
+			return " (unverified)"
+		}
+	}
+}
+
+// recipients returns the users other than us to encrypt a message for.
+// Ratchet keys are only used when they are signed by the key of their user.
+func (c *Client) recipients(ctx context.Context) (map[string]recipient, error) {
+	keys, err := c.Keys(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to fetch recipient keys")
+	}
+
+	recipients := make(map[string]recipient, len(keys))
+	for _, key := range keys {
+		if key.Username == c.username {
+			continue
+		}
+
+		publicKey, err := secure.ParsePublicKey(key.Key)
+		if err != nil {
+			log.Printf("Ignoring invalid key of %s: %v", key.Username, err)
+			continue
+		}
+
+		r := recipient{publicKey: publicKey}
+		if !key.Gateway {
+			if err := secure.Verify(publicKey, key.RatchetKeySignature, key.RatchetKey); err != nil {
+				log.Printf("Ignoring invalid ratchet key of %s: %v", key.Username, err)
+				continue
 			}
-			env, err := c.getEnvelope(message)
+			r.ratchetKey = key.RatchetKey
+		}
+		recipients[key.Username] = r
+	}
+	return recipients, nil
+}
+
+// Users returns the usernames currently logged in to the server.
+func (c *Client) Users(ctx context.Context) ([]string, error) {
+	resp, err := c.chatClient.Users(ctx, &chat.UsersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Usernames, nil
+}
+
+// Send posts a message to the conversation.
+func (c *Client) Send(value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	recipients, err := c.recipients(ctx)
+	if err != nil {
+		return err
+	}
+
+	env, err := c.getEnvelope(chat.Message{
+		Sender: c.username,
+		Value:  value,
+	}, recipients)
+	if err != nil {
+		return err
+	}
+
+	// Envelopes must leave in the order they are stamped in, or the server
+	// takes the later ones for replays.
+	c.sendMtx.Lock()
+	defer c.sendMtx.Unlock()
+
+	if c.stamper != nil {
+		if env.Stamp, err = c.stamper.Stamp(secure.EnvelopeParts(env)...); err != nil {
+			return err
+		}
+	}
+	return c.stream.Send(env)
+}
+
+// Receive blocks until the next message of the conversation arrives. It
+// returns io.EOF once the server closes the conversation. System notices are
+// returned without a sender.
+func (c *Client) Receive() (*chat.Message, error) {
+	env, err := c.stream.Recv()
+	if err != nil {
+		if delay, ok := retryDelay(err); ok {
+			return nil, errors.Errorf("the server is restarting, reconnect in %s", delay)
+		}
+		return nil, err
+	}
+
+	if c.replays != nil {
+		if err := c.replays.Check(env.Stamp, secure.EnvelopeParts(env)...); err != nil {
+			return nil, errors.WithMessage(err, "rejected envelope from server")
+		}
+	}
+
+	if env.Signature != nil {
+		return c.readNotice(env)
+	}
+
+	wrappedKey, ok := env.Keys[c.username]
+	if !ok || wrappedKey == nil {
+		return nil, errors.New("received a message that is not encrypted for us")
+	}
+
+	key, err := c.messageKey(env.Sender, wrappedKey)
+	if err != nil {
+		log.Printf("Failed to read message from %s: %v", env.Sender, err)
+		return &chat.Message{Sender: env.Sender, Value: "(message could not be decrypted)"}, nil
+	}
+
+	decrypted, err := secure.OpenMessage(key, env.Message, []byte(env.Room))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read message")
+	}
+
+	var msg chat.Message
+	err = proto.Unmarshal(decrypted, &msg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read message")
+	}
+
+	note := c.verifySender(env.Sender, env.Room, &msg)
+	msg.Signature = nil
+
+	if msg.Sender != env.Sender {
+		msg.Sender = fmt.Sprintf("%s (claiming to be %s)", env.Sender, msg.Sender)
+	}
+	msg.Sender += note
+	return &msg, nil
+}
+
+// messageKey unwraps the message key sender wrapped for us: with our own
+// key for our messages, through the ratchet session for those of other
+// clients, and with our RSA key for those the server wraps for gateway
+// users.
+func (c *Client) messageKey(sender string, wrapped *chat.WrappedKey) ([]byte, error) {
+	switch {
+	case sender == c.username:
+		return secure.Open(c.selfKey, wrapped.Key, nil)
+	case wrapped.Header != nil:
+		return c.ratchets.unwrap(sender, wrapped, keyAssociatedData(sender, c.username))
+	default:
+		return secure.UnwrapKey(c.privateKey, wrapped.Key)
+	}
+}
+
+// readNotice decrypts a system notice and checks it is signed by the server.
+// A notice announcing a rotation of the server key switches to the new key.
+func (c *Client) readNotice(env *chat.Envelope) (*chat.Message, error) {
+	decrypted, err := secure.Open(c.sessionKey, env.Message, []byte(env.Room))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read notice")
+	}
+
+	if err := secure.Verify(c.publicServerKey, env.Signature, []byte(env.Room), decrypted); err != nil {
+		return nil, errors.New("notice is not signed by the server")
+	}
+
+	var msg chat.Message
+	if err := proto.Unmarshal(decrypted, &msg); err != nil {
+		return nil, errors.WithMessage(err, "failed to read notice")
+	}
+	msg.Sender = ""
+
+	if msg.ServerKey != nil {
+		serverKey, err := secure.ParsePublicKey(msg.ServerKey)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid key announced by server")
+		}
+		c.publicServerKey = serverKey
+		msg.ServerKey = nil
+
+		if c.knownServers != nil {
+			fingerprint, err := secure.KeyFingerprint(serverKey)
 			if err != nil {
-				return err
+				return nil, err
 			}
+			if err := c.knownServers.Trust(c.serverAddress, fingerprint); err != nil {
+				log.Printf("Failed to pin the new server key: %v", err)
+			}
+		}
+	}
+	return &msg, nil
+}
 
-			if err := stream.Send(env); err != nil {
-				return err
+// send executes the lines typed on stdin until it closes or the user logs
+// out.
+func (c *Client) send() error {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if err := c.Execute(scanner.Text(), os.Stdout); err != nil {
+			if err == ErrLoggedOut {
+				return nil
 			}
+			return err
 		}
 	}
 
 	return scanner.Err()
 }
 
-func (c *Client) receive(stream chat.Chat_JoinClient) error {
+func (c *Client) receive() error {
 	for {
-		env, err := stream.Recv()
+		msg, err := c.Receive()
 		if err != nil {
 			if err == io.EOF {
 				return nil
@@ -207,17 +653,6 @@ func (c *Client) receive(stream chat.Chat_JoinClient) error {
 			return err
 		}
 
-		decrypted, err := rsa.DecryptOAEP(hash, rand.Reader, c.privateKey, env.Message, nil)
-		if err != nil {
-			return errors.WithMessage(err, "failed to read message")
-		}
-
-		var msg chat.Message
-		err = proto.Unmarshal(decrypted, &msg)
-		if err != nil {
-			return errors.WithMessage(err, "failed to read message")
-		}
-
 		if msg.Sender != "" {
 			fmt.Printf("%s: %s\n", msg.Sender, msg.Value)
 		} else {
@@ -225,3 +660,15 @@ func (c *Client) receive(stream chat.Chat_JoinClient) error {
 		}
 	}
 }
+
+// retryDelay returns the delay the server asked to reconnect after, if err
+// carries one.
+func retryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if retry, ok := detail.(*errdetails.RetryInfo); ok {
+			delay, err := ptypes.Duration(retry.RetryDelay)
+			return delay, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLoggedOut is returned by Execute once the user logged out.
+var ErrLoggedOut = errors.New("logged out")
+
+// Execute runs a line typed by the user: one of the commands, reporting to
+// out, or else a message posted to the conversation. Lines must be executed
+// one at a time.
+func (c *Client) Execute(line string, out io.Writer) error {
+	args := strings.Fields(line)
+	switch {
+	case line == "/logout":
+		if err := c.Logout(); err != nil {
+			return err
+		}
+		return ErrLoggedOut
+	case len(args) > 0 && args[0] == "/upload":
+		if len(args) != 2 {
+			fmt.Fprintln(out, "usage: /upload <path>")
+			return nil
+		}
+		info, ref, err := c.Upload(context.Background(), args[1])
+		if err != nil {
+			fmt.Fprintf(out, "upload failed: %v\n", err)
+			return nil
+		}
+		fmt.Fprintf(out, "uploaded %s as %s\n", args[1], ref)
+		// The key of the file goes to the room end-to-end encrypted, like
+		// any message.
+		return c.Send(fmt.Sprintf("shared %s, fetch it with /download %s <path>", info.Name, ref))
+	case len(args) > 0 && args[0] == "/download":
+		if len(args) != 3 {
+			fmt.Fprintln(out, "usage: /download <id>#<key> <path>")
+			return nil
+		}
+		info, err := c.Download(context.Background(), args[1], args[2])
+		if err != nil {
+			fmt.Fprintf(out, "download failed: %v\n", err)
+			return nil
+		}
+		fmt.Fprintf(out, "downloaded %s (%s, %d bytes) to %s\n", info.Name, info.ContentType, info.Length, args[2])
+	case len(args) > 0 && args[0] == "/verify":
+		c.verify(args[1:], out)
+	default:
+		return c.Send(line)
+	}
+	return nil
+}
+
+// verify shows the safety number with a user, then marks them verified
+// once the user confirms it matches.
+func (c *Client) verify(args []string, out io.Writer) {
+	switch {
+	case len(args) == 1:
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		number, fingerprint, err := c.SafetyNumber(ctx, args[0])
+		cancel()
+		if err != nil {
+			fmt.Fprintf(out, "verify failed: %v\n", err)
+			return
+		}
+		c.pending[args[0]] = fingerprint
+		fmt.Fprintf(out, "Safety number with %s:\n  %s\nCompare it with %s out of band. If it matches, type /verify %s yes\n", args[0], number, args[0], args[0])
+	case len(args) == 2 && args[1] == "yes" && c.pending[args[0]] != "":
+		if err := c.MarkVerified(args[0], c.pending[args[0]]); err != nil {
+			fmt.Fprintf(out, "verify failed: %v\n", err)
+			return
+		}
+		delete(c.pending, args[0])
+		fmt.Fprintf(out, "%s is now verified\n", args[0])
+	default:
+		fmt.Fprintln(out, "usage: /verify <user>, then /verify <user> yes once the safety numbers match")
+	}
+}
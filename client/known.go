@@ -0,0 +1,118 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+)
+
+// KnownServers pins the key of every server the client has talked to, in a
+// file of "address fingerprint" lines. The first key seen for an address is
+// trusted; a different key afterwards is refused unless the server proves it
+// rotated to it from the pinned key, or until it is trusted deliberately.
+type KnownServers struct {
+	path string
+	mtx  sync.Mutex
+}
+
+// ServerKeyChangedError is returned when a server offers a key other than
+// the one pinned for its address.
+type ServerKeyChangedError struct {
+	Address  string
+	Known    string
+	Offered  string
+	Filename string
+}
+
+func (e *ServerKeyChangedError) Error() string {
+	return fmt.Sprintf(`
+@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@
+@    WARNING: THE KEY OF THE SERVER HAS CHANGED!           @
+@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@
+Someone could be intercepting your conversation. The server
+%s offered the key
+  %s
+but %s pins it to
+  %s
+If the server key was changed deliberately, accept the new key
+with "chat trust".`, e.Address, e.Offered, e.Filename, e.Known)
+}
+
+func NewKnownServers(path string) *KnownServers {
+	return &KnownServers{path: path}
+}
+
+// Check verifies the fingerprint of the key offered by the server at
+// address, pinning it if the server is new. A different key is pinned in
+// place of the known one if the rotations the server presented, oldest
+// first, lead to it from the known key.
+func (k *KnownServers) Check(address, fingerprint string, rotations []*chat.KeyRotation) error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	known, err := readPins(k.path)
+	if err != nil {
+		return err
+	}
+
+	pinned, ok := known[address]
+	switch {
+	case !ok:
+		fmt.Fprintf(os.Stderr, "Trusting the key of %s on first use: %s\n", address, fingerprint)
+		known[address] = fingerprint
+		return writePins(k.path, known)
+	case pinned != fingerprint:
+		if followRotations(pinned, rotations) != fingerprint {
+			return &ServerKeyChangedError{Address: address, Known: pinned, Offered: fingerprint, Filename: k.path}
+		}
+		fmt.Fprintf(os.Stderr, "Following the rotation of the key of %s to %s\n", address, fingerprint)
+		known[address] = fingerprint
+		return writePins(k.path, known)
+	}
+	return nil
+}
+
+// followRotations returns the fingerprint of the key the rotations lead to
+// from the key with the pinned fingerprint. Rotations that do not start at
+// the key reached so far, or are not signed with it, are skipped.
+func followRotations(pinned string, rotations []*chat.KeyRotation) string {
+	for _, rotation := range rotations {
+		previous, err := secure.ParsePublicKey(rotation.PreviousKey)
+		if err != nil {
+			continue
+		}
+		if fingerprint, err := secure.KeyFingerprint(previous); err != nil || fingerprint != pinned {
+			continue
+		}
+		if err := secure.VerifyRotation(rotation.Signature, rotation.PreviousKey, rotation.Key); err != nil {
+			continue
+		}
+
+		key, err := secure.ParsePublicKey(rotation.Key)
+		if err != nil {
+			continue
+		}
+		if pinned, err = secure.KeyFingerprint(key); err != nil {
+			return ""
+		}
+	}
+	return pinned
+}
+
+// Trust pins fingerprint as the key of the server at address, replacing
+// the key known so far.
+func (k *KnownServers) Trust(address, fingerprint string) error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	known, err := readPins(k.path)
+	if err != nil {
+		return err
+	}
+
+	known[address] = fingerprint
+	return writePins(k.path, known)
+}
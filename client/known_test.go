@@ -0,0 +1,86 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+)
+
+type testKey struct {
+	key         *rsa.PrivateKey
+	public      []byte
+	fingerprint string
+}
+
+func newTestKey(t *testing.T) *testKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := secure.MarshalPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := secure.KeyFingerprint(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKey{key: key, public: public, fingerprint: fingerprint}
+}
+
+// rotate records the rotation from previous to key, signed with signer.
+func rotate(t *testing.T, signer, previous, key *testKey) *chat.KeyRotation {
+	t.Helper()
+
+	signature, err := secure.SignRotation(signer.key, previous.public, key.public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &chat.KeyRotation{PreviousKey: previous.public, Key: key.public, Signature: signature}
+}
+
+func TestKnownServersFollowRotations(t *testing.T) {
+	const address = "chat.example.com:8080"
+	first, second, third := newTestKey(t), newTestKey(t), newTestKey(t)
+
+	tests := []struct {
+		name      string
+		rotations []*chat.KeyRotation
+		trusted   bool
+	}{
+		{"no rotations", nil, false},
+		{"chain", []*chat.KeyRotation{rotate(t, first, first, second), rotate(t, second, second, third)}, true},
+		{"skipped rotation", []*chat.KeyRotation{rotate(t, second, second, third)}, false},
+		{"wrong signer", []*chat.KeyRotation{rotate(t, third, first, third)}, false},
+		{"out of order", []*chat.KeyRotation{rotate(t, second, second, third), rotate(t, first, first, second)}, false},
+	}
+	for _, test := range tests {
+		known := NewKnownServers(filepath.Join(t.TempDir(), "known_servers"))
+		if err := known.Check(address, first.fingerprint, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		err := known.Check(address, third.fingerprint, test.rotations)
+		if _, changed := err.(*ServerKeyChangedError); changed == test.trusted {
+			t.Errorf("%s: got %v", test.name, err)
+			continue
+		}
+		if !test.trusted {
+			continue
+		}
+
+		// The new key is pinned, and the old one is refused from now on.
+		if err := known.Check(address, third.fingerprint, nil); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if _, changed := known.Check(address, first.fingerprint, nil).(*ServerKeyChangedError); !changed {
+			t.Errorf("%s: the replaced key is still accepted", test.name)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// readPins reads a file of "name fingerprint" lines.
+func readPins(path string) (map[string]string, error) {
+	pins := make(map[string]string)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read "+filepath.Base(path))
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		pins[fields[0]] = strings.TrimSpace(fields[1])
+	}
+	return pins, scanner.Err()
+}
+
+// writePins replaces the file at path with the given pins.
+func writePins(path string, pins map[string]string) error {
+	names := make([]string, 0, len(pins))
+	for name := range pins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s %s\n", name, pins[name])
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.WithMessage(err, "failed to create config directory")
+	}
+	return ioutil.WriteFile(path, []byte(b.String()), 0600)
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"sync"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/ratchet"
+	"github.com/pkg/errors"
+)
+
+// ratchets holds the ratchet sessions of the client with every other user.
+// Message keys are wrapped for each recipient by the current session with
+// them, so a key compromised later does not expose earlier messages.
+type ratchets struct {
+	mtx sync.Mutex
+
+	// key is the ratchet key published in the key directory. It only lives
+	// as long as the client.
+	key *ratchet.KeyPair
+
+	sessions map[string]*peerSession
+	current  map[string]*peerSession
+}
+
+type peerSession struct {
+	state *ratchet.State
+
+	// id is the ephemeral key of the initiator.
+	id []byte
+	// peerKey is the directory ratchet key of the peer the session is used
+	// with. A new session is started once the peer publishes another one.
+	peerKey   []byte
+	initiated bool
+}
+
+func newRatchets() (*ratchets, error) {
+	key, err := ratchet.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratchets{
+		key:      key,
+		sessions: make(map[string]*peerSession),
+		current:  make(map[string]*peerSession),
+	}, nil
+}
+
+// wrap seals a message key for peer, whose directory ratchet key is
+// peerKey, starting a session with them if needed.
+func (r *ratchets) wrap(peer string, peerKey, messageKey, additionalData []byte) (*chat.WrappedKey, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	session := r.current[peer]
+	if session != nil && session.peerKey == nil {
+		session.peerKey = peerKey
+	}
+	if session == nil || !bytes.Equal(session.peerKey, peerKey) {
+		state, id, err := ratchet.Initiate(rand.Reader, peerKey)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to start ratchet session")
+		}
+
+		session = &peerSession{state: state, id: id, peerKey: peerKey, initiated: true}
+		r.sessions[sessionKey(peer, id)] = session
+		r.current[peer] = session
+	}
+
+	header, sealed, err := session.state.Encrypt(messageKey, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := &chat.WrappedKey{
+		Key: sealed,
+		Header: &chat.RatchetHeader{
+			Session:        session.id,
+			PublicKey:      header.PublicKey,
+			PreviousLength: header.PreviousLength,
+			Number:         header.Number,
+		},
+	}
+	if session.initiated {
+		wrapped.Header.RecipientKey = session.peerKey
+	}
+	return wrapped, nil
+}
+
+// unwrap opens a message key peer sealed for us, accepting the session it
+// belongs to if it is a new one.
+func (r *ratchets) unwrap(peer string, wrapped *chat.WrappedKey, additionalData []byte) ([]byte, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	h := wrapped.Header
+	header := ratchet.Header{
+		PublicKey:      h.PublicKey,
+		PreviousLength: h.PreviousLength,
+		Number:         h.Number,
+	}
+
+	session, ok := r.sessions[sessionKey(peer, h.Session)]
+	if !ok {
+		if !bytes.Equal(h.RecipientKey, r.key.Public) {
+			return nil, errors.New("message belongs to a ratchet session we no longer have")
+		}
+
+		state, err := ratchet.Accept(rand.Reader, r.key, h.Session)
+		if err != nil {
+			return nil, err
+		}
+		session = &peerSession{state: state, id: h.Session}
+	}
+
+	messageKey, err := session.state.Decrypt(header, wrapped.Key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		r.sessions[sessionKey(peer, h.Session)] = session
+		r.current[peer] = session
+	}
+	return messageKey, nil
+}
+
+func sessionKey(peer string, id []byte) string {
+	return peer + "\x00" + string(id)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+)
+
+func newTestRatchets(t *testing.T) *ratchets {
+	t.Helper()
+	r, err := newRatchets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRatchetsWrap(t *testing.T) {
+	alice, bob := newTestRatchets(t), newTestRatchets(t)
+	ad := keyAssociatedData("alice", "bob")
+
+	var wrapped []*chat.WrappedKey
+	for i := byte(0); i < 3; i++ {
+		w, err := alice.wrap("bob", bob.key.Public, []byte{i}, ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrapped = append(wrapped, w)
+	}
+	if !bytes.Equal(wrapped[0].Header.Session, wrapped[2].Header.Session) {
+		t.Fatal("alice started a new session for every message")
+	}
+
+	// The keys can be read in any order, but only once.
+	for _, i := range []int{2, 0, 1} {
+		key, err := bob.unwrap("alice", wrapped[i], ad)
+		if err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if !bytes.Equal(key, []byte{byte(i)}) {
+			t.Errorf("message %d: got key %x", i, key)
+		}
+	}
+	if _, err := bob.unwrap("alice", wrapped[0], ad); err == nil {
+		t.Error("unwrapped a key twice")
+	}
+
+	// Bob answers in the session alice started.
+	reply, err := bob.wrap("alice", alice.key.Public, []byte("reply"), keyAssociatedData("bob", "alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reply.Header.Session, wrapped[0].Header.Session) {
+		t.Error("bob started another session to answer")
+	}
+	if key, err := alice.unwrap("bob", reply, keyAssociatedData("bob", "alice")); err != nil || string(key) != "reply" {
+		t.Errorf("got %q, %v", key, err)
+	}
+}
+
+func TestRatchetsRejectMisdirected(t *testing.T) {
+	alice, bob, carol := newTestRatchets(t), newTestRatchets(t), newTestRatchets(t)
+	ad := keyAssociatedData("alice", "bob")
+
+	wrapped, err := alice.wrap("bob", bob.key.Public, []byte("key"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := carol.unwrap("alice", wrapped, ad); err == nil {
+		t.Error("carol accepted a session started with bob")
+	}
+	// Under another sender the associated data no longer matches.
+	if _, err := bob.unwrap("mallory", wrapped, keyAssociatedData("mallory", "bob")); err == nil {
+		t.Error("bob accepted the key from another sender")
+	}
+	if _, err := bob.unwrap("alice", wrapped, ad); err != nil {
+		t.Errorf("the rejected keys broke the session: %v", err)
+	}
+}
+
+func TestRatchetsNewPeerKey(t *testing.T) {
+	alice, bob := newTestRatchets(t), newTestRatchets(t)
+	ad := keyAssociatedData("alice", "bob")
+
+	first, err := alice.wrap("bob", bob.key.Public, []byte("first"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob logs in again with a new ratchet key.
+	bob = newTestRatchets(t)
+	second, err := alice.wrap("bob", bob.key.Public, []byte("second"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first.Header.Session, second.Header.Session) {
+		t.Fatal("alice kept the session with the old key of bob")
+	}
+
+	if _, err := bob.unwrap("alice", first, ad); err == nil {
+		t.Error("unwrapped a key sealed for the old key of bob")
+	}
+	if key, err := bob.unwrap("alice", second, ad); err != nil || string(key) != "second" {
+		t.Errorf("got %q, %v", key, err)
+	}
+}
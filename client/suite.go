@@ -0,0 +1,74 @@
+package client
+
+import (
+	"crypto/cipher"
+	"crypto/rsa"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+)
+
+// SetCipherSuites sets the suites the client offers at login, in order of
+// preference.
+func (c *Client) SetCipherSuites(suites []secure.Suite) {
+	c.suites = suites
+}
+
+// offer holds the key pairs of the suites the client offers at login until
+// the server has chosen one of them.
+type offer struct {
+	keys   map[string]offeredKey
+	shares []*chat.KeyShare
+	names  []string
+}
+
+type offeredKey struct {
+	suite   secure.Suite
+	private []byte
+	public  []byte
+}
+
+func newOffer(suites []secure.Suite) (*offer, error) {
+	o := &offer{keys: make(map[string]offeredKey, len(suites))}
+	for _, suite := range suites {
+		private, public, err := suite.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+
+		o.keys[suite.Name()] = offeredKey{suite: suite, private: private, public: public}
+		o.shares = append(o.shares, &chat.KeyShare{CipherSuite: suite.Name(), Key: public})
+		o.names = append(o.names, suite.Name())
+	}
+	return o, nil
+}
+
+// accept derives the session key from the answer of the server, after
+// checking that it answered with a protocol version the client speaks,
+// chose one of the offered suites and signed the login transcript with
+// serverKey. An older server may answer with an older version; since the
+// transcript holds the version the client offered, an attacker cannot force
+// one by rewriting the offer or the answer.
+func (o *offer) accept(serverKey *rsa.PublicKey, resp *chat.LoginResponse) (cipher.AEAD, error) {
+	if resp.ProtocolVersion < secure.MinProtocolVersion || resp.ProtocolVersion > secure.ProtocolVersion {
+		return nil, errors.Errorf("server answered with protocol version %d, expected %d to %d", resp.ProtocolVersion, secure.MinProtocolVersion, secure.ProtocolVersion)
+	}
+
+	name := resp.CipherSuite
+	key, ok := o.keys[name]
+	if !ok {
+		return nil, errors.Errorf("server chose cipher suite %q, which was not offered", name)
+	}
+
+	transcript := secure.LoginTranscript(secure.ProtocolVersion, resp.ProtocolVersion, name, o.names, resp.EphemeralKey, key.public)
+	if err := secure.Verify(serverKey, resp.EphemeralKeySignature, transcript...); err != nil {
+		return nil, errors.New("ephemeral key is not signed by the server")
+	}
+
+	sessionKey, err := key.suite.Decapsulate(key.private, resp.EphemeralKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid session key received from server")
+	}
+	return sessionKey, nil
+}
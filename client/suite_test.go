@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+)
+
+// answer plays the server: it picks suite out of o, as offered with
+// offeredVersion, and signs its answer with version.
+func answer(t *testing.T, key *rsa.PrivateKey, o *offer, offeredVersion, version uint32, suite string) *chat.LoginResponse {
+	t.Helper()
+
+	var clientShare []byte
+	for _, share := range o.shares {
+		if share.CipherSuite == suite {
+			clientShare = share.Key
+		}
+	}
+	serverShare, _, err := o.keys[suite].suite.Encapsulate(clientShare)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := secure.Sign(key, secure.LoginTranscript(offeredVersion, version, suite, o.names, serverShare, clientShare)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &chat.LoginResponse{
+		EphemeralKey:          serverShare,
+		EphemeralKeySignature: signature,
+		ProtocolVersion:       version,
+		CipherSuite:           suite,
+	}
+}
+
+func TestOfferAccept(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := newOffer(secure.Suites())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := answer(t, key, o, secure.ProtocolVersion, secure.ProtocolVersion, secure.SuiteX25519MLKEM768AESGCM)
+	if _, err := o.accept(&key.PublicKey, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// An older server answers with its own version.
+	resp = answer(t, key, o, secure.ProtocolVersion, secure.MinProtocolVersion, secure.SuiteX25519AESGCM)
+	if _, err := o.accept(&key.PublicKey, resp); err != nil {
+		t.Errorf("refused protocol version %d: %v", secure.MinProtocolVersion, err)
+	}
+	for _, version := range []uint32{secure.MinProtocolVersion - 1, secure.ProtocolVersion + 1} {
+		resp := answer(t, key, o, secure.ProtocolVersion, version, secure.SuiteX25519AESGCM)
+		if _, err := o.accept(&key.PublicKey, resp); err == nil {
+			t.Errorf("accepted protocol version %d", version)
+		}
+	}
+
+	// The offer was rewritten to an older version, which the server signs
+	// as what the client offered.
+	resp = answer(t, key, o, secure.MinProtocolVersion, secure.MinProtocolVersion, secure.SuiteX25519AESGCM)
+	if _, err := o.accept(&key.PublicKey, resp); err == nil {
+		t.Error("accepted an answer to a downgraded offer")
+	}
+
+	// The answer was rewritten to an older version than the server signed.
+	resp = answer(t, key, o, secure.ProtocolVersion, secure.ProtocolVersion, secure.SuiteX25519MLKEM768AESGCM)
+	resp.ProtocolVersion = secure.MinProtocolVersion
+	if _, err := o.accept(&key.PublicKey, resp); err == nil {
+		t.Error("accepted a version the server did not sign")
+	}
+
+	// The answer was rewritten to another suite than the server signed.
+	resp = answer(t, key, o, secure.ProtocolVersion, secure.ProtocolVersion, secure.SuiteX25519MLKEM768AESGCM)
+	resp.CipherSuite = secure.SuiteX25519AESGCM
+	if _, err := o.accept(&key.PublicKey, resp); err == nil {
+		t.Error("accepted a suite the server did not sign")
+	}
+
+	// The server saw an offer without the post-quantum suite.
+	stripped := *o
+	stripped.names = o.names[1:]
+	resp = answer(t, key, &stripped, secure.ProtocolVersion, secure.ProtocolVersion, secure.SuiteX25519AESGCM)
+	if _, err := o.accept(&key.PublicKey, resp); err == nil {
+		t.Error("accepted an answer to a stripped offer")
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+)
+
+const (
+	chunkSize = 32 << 10
+
+	// fileRefSeparator separates the id of a file from its key in the
+	// references to it.
+	fileRefSeparator = "#"
+
+	// fileAdditionalData is authenticated along with the content of files,
+	// so that their ciphertext cannot pass for that of a message.
+	fileAdditionalData = "file"
+)
+
+// Upload encrypts the file at path with a fresh key and sends it to the
+// server, which only stores the ciphertext. It returns the file info and
+// the reference others can download it with, made of the id of the file and
+// its key.
+func (c *Client) Upload(ctx context.Context, path string) (*chat.FileInfo, string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, sealed, err := secure.SealMessage(content, []byte(fileAdditionalData))
+	if err != nil {
+		return nil, "", err
+	}
+
+	digest := sha256.Sum256(sealed)
+	info := &chat.FileInfo{
+		Name:        filepath.Base(path),
+		ContentType: contentType(path, content),
+		Length:      int64(len(sealed)),
+		Sha256:      digest[:],
+	}
+
+	stream, err := c.chatClient.Upload(c.outgoingContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := stream.Send(&chat.UploadRequest{Data: &chat.UploadRequest_Info{Info: info}}); err != nil && err != io.EOF {
+		return nil, "", err
+	}
+
+	for len(sealed) > 0 {
+		n := chunkSize
+		if n > len(sealed) {
+			n = len(sealed)
+		}
+		chunk := &chat.UploadRequest{Data: &chat.UploadRequest_Chunk{Chunk: sealed[:n]}}
+		if err := stream.Send(chunk); err != nil {
+			// The server ended the upload early, CloseAndRecv tells why.
+			if err == io.EOF {
+				break
+			}
+			return nil, "", err
+		}
+		sealed = sealed[n:]
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, "", err
+	}
+	return info, resp.Id + fileRefSeparator + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// Download fetches the file of ref into path, verifying the size and
+// checksum of its ciphertext and decrypting it before it is put in place.
+// The returned info describes the decrypted file.
+func (c *Client) Download(ctx context.Context, ref, path string) (*chat.FileInfo, error) {
+	id, key, err := parseFileRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.chatClient.Download(c.outgoingContext(ctx), &chat.DownloadRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	info := resp.GetInfo()
+	if info == nil {
+		return nil, errors.New("server did not send the file info")
+	}
+
+	var sealed bytes.Buffer
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		chunk := resp.GetChunk()
+		if int64(sealed.Len()+len(chunk)) > info.Length {
+			return nil, errors.New("download is larger than announced")
+		}
+		sealed.Write(chunk)
+	}
+
+	digest := sha256.Sum256(sealed.Bytes())
+	if int64(sealed.Len()) != info.Length || !bytes.Equal(digest[:], info.Sha256) {
+		return nil, errors.New("download does not match its checksum")
+	}
+
+	content, err := secure.OpenMessage(key, sealed.Bytes(), []byte(fileAdditionalData))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decrypt download")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".download-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(content)
+	return &chat.FileInfo{
+		Name:        info.Name,
+		ContentType: info.ContentType,
+		Length:      int64(len(content)),
+		Sha256:      checksum[:],
+	}, nil
+}
+
+// parseFileRef splits a reference returned by Upload into the id of the
+// file and its key.
+func parseFileRef(ref string) (string, []byte, error) {
+	i := strings.LastIndex(ref, fileRefSeparator)
+	if i < 0 {
+		return "", nil, errors.New("file reference has no key, expected <id>" + fileRefSeparator + "<key>")
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(ref[i+1:])
+	if err != nil || len(key) != secure.KeySize {
+		return "", nil, errors.New("invalid file key")
+	}
+	return ref[:i], key, nil
+}
+
+// contentType guesses the type of the file at path from its extension, or
+// else from its content.
+func contentType(path string, content []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(content)
+}
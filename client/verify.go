@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"log"
+	"sync"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+)
+
+// VerifiedUsers remembers the identity fingerprint of every user whose
+// safety number was compared, in a file of "username fingerprint" lines.
+type VerifiedUsers struct {
+	path string
+	mtx  sync.Mutex
+}
+
+func NewVerifiedUsers(path string) *VerifiedUsers {
+	return &VerifiedUsers{path: path}
+}
+
+// Lookup returns the fingerprint username was verified with, if any.
+func (v *VerifiedUsers) Lookup(username string) (string, bool, error) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	verified, err := readPins(v.path)
+	if err != nil {
+		return "", false, err
+	}
+
+	fingerprint, ok := verified[username]
+	return fingerprint, ok, nil
+}
+
+// Verify records that username was verified with fingerprint.
+func (v *VerifiedUsers) Verify(username, fingerprint string) error {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	verified, err := readPins(v.path)
+	if err != nil {
+		return err
+	}
+
+	verified[username] = fingerprint
+	return writePins(v.path, verified)
+}
+
+// TrackVerifiedUsers makes the client mark the messages of verified users
+// and warn when their keys change.
+func (c *Client) TrackVerifiedUsers(verifiedUsers *VerifiedUsers) {
+	c.verifiedUsers = verifiedUsers
+}
+
+// SafetyNumber derives the safety number of the client with username from
+// the identity keys the directory lists for them, together with the
+// fingerprint of those keys to verify them with once the numbers match.
+func (c *Client) SafetyNumber(ctx context.Context, username string) (string, string, error) {
+	if _, err := c.Keys(ctx); err != nil {
+		return "", "", err
+	}
+
+	c.directoryMtx.Lock()
+	key := c.directory[username]
+	c.directoryMtx.Unlock()
+
+	if key == nil || key.Gateway {
+		return "", "", errors.Errorf("%s has no identity keys to verify", username)
+	}
+
+	theirs, err := identityKeys(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	ours, err := x509.MarshalPKIXPublicKey(&c.privateKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	number := secure.SafetyNumber(
+		c.username, [][]byte{ours, c.signingKey.Public().(ed25519.PublicKey)},
+		username, theirs,
+	)
+	return number, secure.Fingerprint(theirs...), nil
+}
+
+// MarkVerified records username as verified with the keys of fingerprint.
+func (c *Client) MarkVerified(username, fingerprint string) error {
+	if c.verifiedUsers == nil {
+		return errors.New("verified users are not tracked")
+	}
+	return c.verifiedUsers.Verify(username, fingerprint)
+}
+
+// verificationNote returns the note to flag the sender of a verified
+// message with, according to whether key is the one they were verified
+// with.
+func (c *Client) verificationNote(key *chat.PublicKey) string {
+	switch c.checkVerified(key) {
+	case verifiedKey:
+		return " (verified)"
+	case changedKey:
+		return " (KEY CHANGED since verification)"
+	default:
+		return ""
+	}
+}
+
+type verification int
+
+const (
+	notVerified verification = iota
+	verifiedKey
+	changedKey
+)
+
+func (c *Client) checkVerified(key *chat.PublicKey) verification {
+	if c.verifiedUsers == nil || key.Gateway {
+		return notVerified
+	}
+
+	pinned, ok, err := c.verifiedUsers.Lookup(key.Username)
+	if err != nil {
+		log.Printf("Failed to read verified users: %v", err)
+		return notVerified
+	}
+	if !ok {
+		return notVerified
+	}
+
+	keys, err := identityKeys(key)
+	if err != nil || secure.Fingerprint(keys...) != pinned {
+		return changedKey
+	}
+	return verifiedKey
+}
+
+// identityKeys returns the identity keys of a directory entry in the form
+// they are fingerprinted in.
+func identityKeys(key *chat.PublicKey) ([][]byte, error) {
+	block, _ := pem.Decode(key.Key)
+	if block == nil {
+		return nil, errors.Errorf("invalid key of %s", key.Username)
+	}
+	return [][]byte{block.Bytes, key.SigningKey}, nil
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	cli "github.com/jawher/mow.cli"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configEnv  = "CHAT_CONFIG"
+	profileEnv = "CHAT_PROFILE"
+)
+
+// config is a YAML configuration file holding a section of option values
+// per command, keyed by the names of the options:
+//
+//	server:
+//	  address: 0.0.0.0:8090
+//	  motd: Welcome!
+//	client:
+//	  profile: work
+//	  profiles:
+//	    work:
+//	      serverAddress: chat.example.com:8090
+//	      username: alice
+//
+// The values only replace the defaults of the options, so environment
+// variables and flags still take precedence over them.
+type config struct {
+	path     string
+	sections map[string]map[string]interface{}
+	errs     []string
+	strict   []*configSection
+}
+
+// configSections are the sections a config file may have.
+var configSections = []string{"admin", "client", "server"}
+
+// loadConfig reads the config file named by the --config flag in args or
+// by $CHAT_CONFIG, or else config.yaml in the user config directory if it
+// exists. Options are declared before they are parsed, so the flag is
+// looked up in args directly.
+func loadConfig(args []string) (*config, error) {
+	path, ok := flagValue(args, "config")
+	if !ok {
+		path = os.Getenv(configEnv)
+	}
+
+	explicit := path != ""
+	path, err := configPath(path, "config.yaml")
+	if err != nil {
+		if explicit {
+			return nil, err
+		}
+		return &config{}, nil
+	}
+
+	cfg, err := readConfig(path)
+	if os.IsNotExist(errors.Cause(err)) && !explicit {
+		return &config{path: path}, nil
+	}
+	return cfg, err
+}
+
+func readConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{path: path}
+	if err := yaml.Unmarshal(data, &cfg.sections); err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("invalid config file %s", path))
+	}
+	return cfg, nil
+}
+
+// section returns the option values of command.
+func (c *config) section(command string) *configSection {
+	return &configSection{config: c, name: command, values: c.sections[command], read: map[string]bool{}}
+}
+
+// profile returns the option values of the client, those of the profile
+// named by the --profile flag in args, $CHAT_PROFILE or the profile key of
+// the client section taking precedence over the rest of the section.
+func (c *config) profile(args []string) *configSection {
+	client := c.section("client")
+	client.read["profile"] = true
+	client.read["profiles"] = true
+
+	name, ok := flagValue(args, "profile")
+	if !ok {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" {
+		name = client.String("profile", "")
+	}
+	if name == "" {
+		return client
+	}
+
+	profiles, _ := client.values["profiles"].(map[string]interface{})
+	profile, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		c.errs = append(c.errs, fmt.Sprintf("unknown client profile %q", name))
+		return client
+	}
+
+	section := &configSection{
+		config:  c,
+		name:    "client.profiles." + name,
+		values:  make(map[string]interface{}, len(client.values)+len(profile)),
+		read:    map[string]bool{"profile": true, "profiles": true},
+		sources: make(map[string]string, len(client.values)),
+	}
+	for key, value := range client.values {
+		section.values[key] = value
+		section.sources[key] = "client"
+	}
+	for key, value := range profile {
+		section.values[key] = value
+		delete(section.sources, key)
+	}
+	return section
+}
+
+// configOption declares the --config option the file was loaded from, and
+// checks the values read from it before cmd runs.
+func (c *config) configOption(cmd *cli.Cmd) {
+	cmd.String(cli.StringOpt{
+		Name:   "config",
+		Value:  c.path,
+		Desc:   "Config file (defaults to config.yaml in the user config directory)",
+		EnvVar: configEnv,
+	})
+	cmd.Before = func() {
+		if err := c.Err(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// profileOption declares the --profile option the client profile was
+// chosen with.
+func (c *config) profileOption(cmd *cli.Cmd) {
+	cmd.String(cli.StringOpt{
+		Name:   "profile",
+		Value:  "",
+		Desc:   "Profile of the client section of the config file to use",
+		EnvVar: profileEnv,
+	})
+}
+
+// Err reports the values of the file that did not fit their options, the
+// sections of the file no command reads and the keys of strict sections no
+// option was read from.
+func (c *config) Err() error {
+	errs := append([]string(nil), c.errs...)
+	for name := range c.sections {
+		if !contains(configSections, name) {
+			errs = append(errs, fmt.Sprintf("unknown section %q", name))
+		}
+	}
+	for _, section := range c.strict {
+		errs = append(errs, section.unknown()...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs[len(c.errs):])
+	return errors.Errorf("%s: %s", c.path, strings.Join(errs, "; "))
+}
+
+// configSection holds the option values of a command. Its getters return
+// the value of an option if the section sets it, and def otherwise, and
+// record the option as known.
+type configSection struct {
+	config *config
+	name   string
+	values map[string]interface{}
+	read   map[string]bool
+	// sources names the section a value was inherited from, if not name.
+	sources map[string]string
+}
+
+// strict has the keys of the section that no option is read from reported
+// as unknown. Commands reading only some of the options of a section shared
+// with others leave it lenient.
+func (s *configSection) strict() {
+	s.config.strict = append(s.config.strict, s)
+}
+
+func (s *configSection) unknown() []string {
+	var errs []string
+	for key := range s.values {
+		if s.read[key] {
+			continue
+		}
+		name, ok := s.sources[key]
+		if !ok {
+			name = s.name
+		}
+		errs = append(errs, fmt.Sprintf("unknown option %s.%s", name, key))
+	}
+	return errs
+}
+
+func (s *configSection) value(option string) (interface{}, bool) {
+	s.read[option] = true
+	value, ok := s.values[option]
+	return value, ok && value != nil
+}
+
+func (s *configSection) String(option, def string) string {
+	value, ok := s.value(option)
+	if !ok {
+		return def
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value
+	case int, float64, bool:
+		return fmt.Sprint(value)
+	}
+	s.invalid(option, "a string")
+	return def
+}
+
+func (s *configSection) Bool(option string, def bool) bool {
+	value, ok := s.value(option)
+	if !ok {
+		return def
+	}
+
+	if value, ok := value.(bool); ok {
+		return value
+	}
+	s.invalid(option, "true or false")
+	return def
+}
+
+func (s *configSection) Int(option string, def int) int {
+	value, ok := s.value(option)
+	if !ok {
+		return def
+	}
+
+	if value, ok := value.(int); ok {
+		return value
+	}
+	s.invalid(option, "an integer")
+	return def
+}
+
+// Strings accepts a list or a single string.
+func (s *configSection) Strings(option string, def []string) []string {
+	value, ok := s.value(option)
+	if !ok {
+		return def
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			str, ok := v.(string)
+			if !ok {
+				s.invalid(option, "a list of strings")
+				return def
+			}
+			values = append(values, str)
+		}
+		return values
+	}
+	s.invalid(option, "a list of strings")
+	return def
+}
+
+func (s *configSection) invalid(option, expected string) {
+	s.config.errs = append(s.config.errs, fmt.Sprintf("%s.%s must be %s", s.name, option, expected))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value given to the flag name in args, as either
+// --name value or --name=value.
+func flagValue(args []string, name string) (string, bool) {
+	flag := "--" + name
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "", false
+		case arg == flag && i+1 < len(args):
+			return args[i+1], true
+		case strings.HasPrefix(arg, flag+"="):
+			return strings.TrimPrefix(arg, flag+"="), true
+		}
+	}
+	return "", false
+}
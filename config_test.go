@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `
+client:
+  serverAddress: chat.example.com:8090
+  profiles:
+    work:
+      serverAddress: work.example.com:8090
+      username: alice
+`
+
+func writeConfig(t *testing.T, content string) *config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestConfigProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		address string
+		err     string
+	}{
+		{"no profile", nil, "chat.example.com:8090", ""},
+		{"empty profile", []string{"--profile", ""}, "chat.example.com:8090", ""},
+		{"profile", []string{"--profile", "work"}, "work.example.com:8090", ""},
+		{"unknown profile", []string{"--profile=home"}, "chat.example.com:8090", `unknown client profile "home"`},
+	}
+	for _, test := range tests {
+		cfg := writeConfig(t, profilesConfig)
+		conf := cfg.profile(test.args)
+		conf.strict()
+
+		if address := conf.String("serverAddress", ""); address != test.address {
+			t.Errorf("%s: got address %q, want %q", test.name, address, test.address)
+		}
+		conf.String("username", "")
+
+		err := cfg.Err()
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: got %v, want %q", test.name, err, test.err)
+		case err != nil && strings.Contains(err.Error(), "unknown option"):
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestConfigUnknownOption(t *testing.T) {
+	cfg := writeConfig(t, profilesConfig+"    home:\n      usrname: bob\n")
+	conf := cfg.profile([]string{"--profile", "home"})
+	conf.strict()
+	conf.String("serverAddress", "")
+	conf.String("username", "")
+
+	want := cfg.path + ": unknown option client.profiles.home.usrname"
+	if err := cfg.Err(); err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
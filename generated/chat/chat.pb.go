@@ -9,11 +9,39 @@
 
 	It has these top-level messages:
 		LoginRequest
+		KeyShare
 		LoginResponse
+		KeyRotation
 		LogoutRequest
 		LogoutResponse
+		UsersRequest
+		UsersResponse
+		KeysRequest
+		PublicKey
+		KeysResponse
 		Message
 		Envelope
+		WrappedKey
+		RatchetHeader
+		FileInfo
+		UploadRequest
+		UploadResponse
+		DownloadRequest
+		DownloadResponse
+		ServerKeyRequest
+		ServerKeyResponse
+		ListSessionsRequest
+		SessionInfo
+		ListSessionsResponse
+		DisconnectRequest
+		DisconnectResponse
+		AnnounceRequest
+		AnnounceResponse
+		StatsRequest
+		StatsResponse
+		GetSettingsRequest
+		Settings
+		UpdateSettingsRequest
 */
 package chat
 
@@ -42,6 +70,22 @@ const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 type LoginRequest struct {
 	Username  string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
 	ClientKey []byte `protobuf:"bytes,2,opt,name=client_key,json=clientKey,proto3" json:"client_key,omitempty"`
+	// The client half of the ephemeral X25519 exchange the session key is
+	// derived from.
+	EphemeralKey []byte `protobuf:"bytes,3,opt,name=ephemeral_key,json=ephemeralKey,proto3" json:"ephemeral_key,omitempty"`
+	// The X25519 key other clients start ratchet sessions against, signed
+	// (RSA-PSS over its SHA-256) with the client key.
+	RatchetKey          []byte `protobuf:"bytes,4,opt,name=ratchet_key,json=ratchetKey,proto3" json:"ratchet_key,omitempty"`
+	RatchetKeySignature []byte `protobuf:"bytes,5,opt,name=ratchet_key_signature,json=ratchetKeySignature,proto3" json:"ratchet_key_signature,omitempty"`
+	// The Ed25519 key the client signs its messages with.
+	SigningKey []byte `protobuf:"bytes,6,opt,name=signing_key,json=signingKey,proto3" json:"signing_key,omitempty"`
+	// The version of the login protocol the client speaks. Clients from
+	// before versioning leave it 0 and only know the x25519-aes256gcm suite,
+	// keyed by ephemeral_key.
+	ProtocolVersion uint32 `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The cipher suites the client supports, in order of preference, each
+	// with the key the session key is derived from if it is chosen.
+	KeyShares []*KeyShare `protobuf:"bytes,8,rep,name=key_shares,json=keyShares" json:"key_shares,omitempty"`
 }
 
 func (m *LoginRequest) Reset()                    { *m = LoginRequest{} }
@@ -63,14 +107,94 @@ func (m *LoginRequest) GetClientKey() []byte {
 	return nil
 }
 
+func (m *LoginRequest) GetEphemeralKey() []byte {
+	if m != nil {
+		return m.EphemeralKey
+	}
+	return nil
+}
+
+func (m *LoginRequest) GetRatchetKey() []byte {
+	if m != nil {
+		return m.RatchetKey
+	}
+	return nil
+}
+
+func (m *LoginRequest) GetRatchetKeySignature() []byte {
+	if m != nil {
+		return m.RatchetKeySignature
+	}
+	return nil
+}
+
+func (m *LoginRequest) GetSigningKey() []byte {
+	if m != nil {
+		return m.SigningKey
+	}
+	return nil
+}
+
+func (m *LoginRequest) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *LoginRequest) GetKeyShares() []*KeyShare {
+	if m != nil {
+		return m.KeyShares
+	}
+	return nil
+}
+
+type KeyShare struct {
+	CipherSuite string `protobuf:"bytes,1,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	Key         []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *KeyShare) Reset()                    { *m = KeyShare{} }
+func (m *KeyShare) String() string            { return proto.CompactTextString(m) }
+func (*KeyShare) ProtoMessage()               {}
+func (*KeyShare) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{1} }
+
+func (m *KeyShare) GetCipherSuite() string {
+	if m != nil {
+		return m.CipherSuite
+	}
+	return ""
+}
+
+func (m *KeyShare) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
 type LoginResponse struct {
 	ServerKey []byte `protobuf:"bytes,1,opt,name=server_key,json=serverKey,proto3" json:"server_key,omitempty"`
+	// The server share of the chosen suite, signed with the server key. From
+	// protocol version 1 the signature covers the login transcript: the
+	// chosen suite, the offered suites and both shares.
+	EphemeralKey          []byte `protobuf:"bytes,2,opt,name=ephemeral_key,json=ephemeralKey,proto3" json:"ephemeral_key,omitempty"`
+	EphemeralKeySignature []byte `protobuf:"bytes,3,opt,name=ephemeral_key_signature,json=ephemeralKeySignature,proto3" json:"ephemeral_key_signature,omitempty"`
+	// The version of the login protocol the server answers with, the lower
+	// of its own and the client's.
+	ProtocolVersion uint32 `protobuf:"varint,4,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The suite the server chose from the key shares of the client.
+	CipherSuite string `protobuf:"bytes,5,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	// The rotations of the server key, oldest first, so that a client that
+	// pinned a key the server has replaced since can follow them to the
+	// current key.
+	KeyRotations []*KeyRotation `protobuf:"bytes,6,rep,name=key_rotations,json=keyRotations" json:"key_rotations,omitempty"`
 }
 
 func (m *LoginResponse) Reset()                    { *m = LoginResponse{} }
 func (m *LoginResponse) String() string            { return proto.CompactTextString(m) }
 func (*LoginResponse) ProtoMessage()               {}
-func (*LoginResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{1} }
+func (*LoginResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{2} }
 
 func (m *LoginResponse) GetServerKey() []byte {
 	if m != nil {
@@ -79,6 +203,75 @@ func (m *LoginResponse) GetServerKey() []byte {
 	return nil
 }
 
+func (m *LoginResponse) GetEphemeralKey() []byte {
+	if m != nil {
+		return m.EphemeralKey
+	}
+	return nil
+}
+
+func (m *LoginResponse) GetEphemeralKeySignature() []byte {
+	if m != nil {
+		return m.EphemeralKeySignature
+	}
+	return nil
+}
+
+func (m *LoginResponse) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *LoginResponse) GetCipherSuite() string {
+	if m != nil {
+		return m.CipherSuite
+	}
+	return ""
+}
+
+func (m *LoginResponse) GetKeyRotations() []*KeyRotation {
+	if m != nil {
+		return m.KeyRotations
+	}
+	return nil
+}
+
+// KeyRotation records that the server replaced previous_key with key,
+// signed with previous_key.
+type KeyRotation struct {
+	PreviousKey []byte `protobuf:"bytes,1,opt,name=previous_key,json=previousKey,proto3" json:"previous_key,omitempty"`
+	Key         []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Signature   []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *KeyRotation) Reset()                    { *m = KeyRotation{} }
+func (m *KeyRotation) String() string            { return proto.CompactTextString(m) }
+func (*KeyRotation) ProtoMessage()               {}
+func (*KeyRotation) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{3} }
+
+func (m *KeyRotation) GetPreviousKey() []byte {
+	if m != nil {
+		return m.PreviousKey
+	}
+	return nil
+}
+
+func (m *KeyRotation) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *KeyRotation) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type LogoutRequest struct {
 	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
 }
@@ -86,7 +279,7 @@ type LogoutRequest struct {
 func (m *LogoutRequest) Reset()                    { *m = LogoutRequest{} }
 func (m *LogoutRequest) String() string            { return proto.CompactTextString(m) }
 func (*LogoutRequest) ProtoMessage()               {}
-func (*LogoutRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{2} }
+func (*LogoutRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{4} }
 
 func (m *LogoutRequest) GetUsername() string {
 	if m != nil {
@@ -101,17 +294,132 @@ type LogoutResponse struct {
 func (m *LogoutResponse) Reset()                    { *m = LogoutResponse{} }
 func (m *LogoutResponse) String() string            { return proto.CompactTextString(m) }
 func (*LogoutResponse) ProtoMessage()               {}
-func (*LogoutResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{3} }
+func (*LogoutResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{5} }
+
+type UsersRequest struct {
+}
+
+func (m *UsersRequest) Reset()                    { *m = UsersRequest{} }
+func (m *UsersRequest) String() string            { return proto.CompactTextString(m) }
+func (*UsersRequest) ProtoMessage()               {}
+func (*UsersRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{6} }
+
+type UsersResponse struct {
+	Usernames []string `protobuf:"bytes,1,rep,name=usernames" json:"usernames,omitempty"`
+}
+
+func (m *UsersResponse) Reset()                    { *m = UsersResponse{} }
+func (m *UsersResponse) String() string            { return proto.CompactTextString(m) }
+func (*UsersResponse) ProtoMessage()               {}
+func (*UsersResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{7} }
+
+func (m *UsersResponse) GetUsernames() []string {
+	if m != nil {
+		return m.Usernames
+	}
+	return nil
+}
+
+type KeysRequest struct {
+}
+
+func (m *KeysRequest) Reset()                    { *m = KeysRequest{} }
+func (m *KeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*KeysRequest) ProtoMessage()               {}
+func (*KeysRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{8} }
+
+type PublicKey struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Key      []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// Gateway keys belong to the server, which reads the messages on behalf
+	// of users connected through a gateway such as IRC.
+	Gateway bool `protobuf:"varint,3,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	// The ratchet key of the user and its signature with key. Gateway users
+	// have none.
+	RatchetKey          []byte `protobuf:"bytes,4,opt,name=ratchet_key,json=ratchetKey,proto3" json:"ratchet_key,omitempty"`
+	RatchetKeySignature []byte `protobuf:"bytes,5,opt,name=ratchet_key_signature,json=ratchetKeySignature,proto3" json:"ratchet_key_signature,omitempty"`
+	SigningKey          []byte `protobuf:"bytes,6,opt,name=signing_key,json=signingKey,proto3" json:"signing_key,omitempty"`
+}
+
+func (m *PublicKey) Reset()                    { *m = PublicKey{} }
+func (m *PublicKey) String() string            { return proto.CompactTextString(m) }
+func (*PublicKey) ProtoMessage()               {}
+func (*PublicKey) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{9} }
+
+func (m *PublicKey) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *PublicKey) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *PublicKey) GetGateway() bool {
+	if m != nil {
+		return m.Gateway
+	}
+	return false
+}
+
+func (m *PublicKey) GetRatchetKey() []byte {
+	if m != nil {
+		return m.RatchetKey
+	}
+	return nil
+}
+
+func (m *PublicKey) GetRatchetKeySignature() []byte {
+	if m != nil {
+		return m.RatchetKeySignature
+	}
+	return nil
+}
+
+func (m *PublicKey) GetSigningKey() []byte {
+	if m != nil {
+		return m.SigningKey
+	}
+	return nil
+}
+
+type KeysResponse struct {
+	Keys []*PublicKey `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *KeysResponse) Reset()                    { *m = KeysResponse{} }
+func (m *KeysResponse) String() string            { return proto.CompactTextString(m) }
+func (*KeysResponse) ProtoMessage()               {}
+func (*KeysResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{10} }
+
+func (m *KeysResponse) GetKeys() []*PublicKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
 
 type Message struct {
 	Sender string `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
 	Value  string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// The Ed25519 signature of the sender over sender, room and value, or the
+	// signature of the server key over the same for users of a gateway. It is
+	// encrypted along with the rest of the message.
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Set on the notice announcing a rotation of the server key to the new
+	// key, signed with the one it replaces.
+	ServerKey []byte `protobuf:"bytes,4,opt,name=server_key,json=serverKey,proto3" json:"server_key,omitempty"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
 func (m *Message) String() string            { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{4} }
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{11} }
 
 func (m *Message) GetSender() string {
 	if m != nil {
@@ -127,14 +435,41 @@ func (m *Message) GetValue() string {
 	return ""
 }
 
+func (m *Message) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Message) GetServerKey() []byte {
+	if m != nil {
+		return m.ServerKey
+	}
+	return nil
+}
+
+// Messages between users are encrypted end-to-end: message is sealed with a
+// message key that is wrapped in keys for each recipient, and the server
+// only routes it, setting sender to the authenticated user. System notices
+// come from the server instead: message is sealed with the session key and
+// signed with the server key.
 type Envelope struct {
-	Message []byte `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Message   []byte                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Room      string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+	Keys      map[string]*WrappedKey `protobuf:"bytes,3,rep,name=keys" json:"keys,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	Sender    string                 `protobuf:"bytes,4,opt,name=sender,proto3" json:"sender,omitempty"`
+	Signature []byte                 `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The sequence number and timestamp of the envelope on the stream, sealed
+	// with the session key over the rest of the envelope, so that it
+	// cannot be sent again or late. Set from protocol version 2.
+	Stamp []byte `protobuf:"bytes,6,opt,name=stamp,proto3" json:"stamp,omitempty"`
 }
 
 func (m *Envelope) Reset()                    { *m = Envelope{} }
 func (m *Envelope) String() string            { return proto.CompactTextString(m) }
 func (*Envelope) ProtoMessage()               {}
-func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{5} }
+func (*Envelope) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{12} }
 
 func (m *Envelope) GetMessage() []byte {
 	if m != nil {
@@ -143,423 +478,6708 @@ func (m *Envelope) GetMessage() []byte {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*LoginRequest)(nil), "chat.LoginRequest")
-	proto.RegisterType((*LoginResponse)(nil), "chat.LoginResponse")
-	proto.RegisterType((*LogoutRequest)(nil), "chat.LogoutRequest")
-	proto.RegisterType((*LogoutResponse)(nil), "chat.LogoutResponse")
-	proto.RegisterType((*Message)(nil), "chat.Message")
-	proto.RegisterType((*Envelope)(nil), "chat.Envelope")
+func (m *Envelope) GetRoom() string {
+	if m != nil {
+		return m.Room
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for Chat service
-
-type ChatClient interface {
-	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
-	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error)
-	Join(ctx context.Context, opts ...grpc.CallOption) (Chat_JoinClient, error)
+func (m *Envelope) GetKeys() map[string]*WrappedKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
 }
 
-type chatClient struct {
-	cc *grpc.ClientConn
+func (m *Envelope) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
 }
 
-func NewChatClient(cc *grpc.ClientConn) ChatClient {
-	return &chatClient{cc}
+func (m *Envelope) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
 }
 
-func (c *chatClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
-	out := new(LoginResponse)
-	err := grpc.Invoke(ctx, "/chat.Chat/Login", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Envelope) GetStamp() []byte {
+	if m != nil {
+		return m.Stamp
 	}
-	return out, nil
+	return nil
 }
 
-func (c *chatClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error) {
-	out := new(LogoutResponse)
-	err := grpc.Invoke(ctx, "/chat.Chat/Logout", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+// A message key wrapped for one recipient. Between clients it is sealed by
+// the ratchet session of the header; keys for gateway users and the keys
+// the server wraps itself are encrypted with RSA-OAEP and have no header.
+type WrappedKey struct {
+	Key    []byte         `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Header *RatchetHeader `protobuf:"bytes,2,opt,name=header" json:"header,omitempty"`
+}
+
+func (m *WrappedKey) Reset()                    { *m = WrappedKey{} }
+func (m *WrappedKey) String() string            { return proto.CompactTextString(m) }
+func (*WrappedKey) ProtoMessage()               {}
+func (*WrappedKey) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{13} }
+
+func (m *WrappedKey) GetKey() []byte {
+	if m != nil {
+		return m.Key
 	}
-	return out, nil
+	return nil
 }
 
-func (c *chatClient) Join(ctx context.Context, opts ...grpc.CallOption) (Chat_JoinClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_Chat_serviceDesc.Streams[0], c.cc, "/chat.Chat/Join", opts...)
-	if err != nil {
-		return nil, err
+func (m *WrappedKey) GetHeader() *RatchetHeader {
+	if m != nil {
+		return m.Header
 	}
-	x := &chatJoinClient{stream}
-	return x, nil
+	return nil
 }
 
-type Chat_JoinClient interface {
-	Send(*Envelope) error
-	Recv() (*Envelope, error)
-	grpc.ClientStream
+type RatchetHeader struct {
+	// The ephemeral key the initiator started the session with, which
+	// identifies it.
+	Session []byte `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	// The ratchet key of the responder, set on messages of the initiator.
+	RecipientKey   []byte `protobuf:"bytes,2,opt,name=recipient_key,json=recipientKey,proto3" json:"recipient_key,omitempty"`
+	PublicKey      []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PreviousLength uint32 `protobuf:"varint,4,opt,name=previous_length,json=previousLength,proto3" json:"previous_length,omitempty"`
+	Number         uint32 `protobuf:"varint,5,opt,name=number,proto3" json:"number,omitempty"`
 }
 
-type chatJoinClient struct {
-	grpc.ClientStream
+func (m *RatchetHeader) Reset()                    { *m = RatchetHeader{} }
+func (m *RatchetHeader) String() string            { return proto.CompactTextString(m) }
+func (*RatchetHeader) ProtoMessage()               {}
+func (*RatchetHeader) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{14} }
+
+func (m *RatchetHeader) GetSession() []byte {
+	if m != nil {
+		return m.Session
+	}
+	return nil
 }
 
-func (x *chatJoinClient) Send(m *Envelope) error {
-	return x.ClientStream.SendMsg(m)
+func (m *RatchetHeader) GetRecipientKey() []byte {
+	if m != nil {
+		return m.RecipientKey
+	}
+	return nil
 }
 
-func (x *chatJoinClient) Recv() (*Envelope, error) {
-	m := new(Envelope)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *RatchetHeader) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
 	}
-	return m, nil
+	return nil
 }
 
-// Server API for Chat service
+func (m *RatchetHeader) GetPreviousLength() uint32 {
+	if m != nil {
+		return m.PreviousLength
+	}
+	return 0
+}
 
-type ChatServer interface {
-	Login(context.Context, *LoginRequest) (*LoginResponse, error)
-	Logout(context.Context, *LogoutRequest) (*LogoutResponse, error)
-	Join(Chat_JoinServer) error
+func (m *RatchetHeader) GetNumber() uint32 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
 }
 
-func RegisterChatServer(s *grpc.Server, srv ChatServer) {
-	s.RegisterService(&_Chat_serviceDesc, srv)
+type FileInfo struct {
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Length      int64  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Sha256      []byte `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
 }
 
-func _Chat_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *FileInfo) Reset()                    { *m = FileInfo{} }
+func (m *FileInfo) String() string            { return proto.CompactTextString(m) }
+func (*FileInfo) ProtoMessage()               {}
+func (*FileInfo) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{15} }
+
+func (m *FileInfo) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	if interceptor == nil {
-		return srv.(ChatServer).Login(ctx, in)
+	return ""
+}
+
+func (m *FileInfo) GetContentType() string {
+	if m != nil {
+		return m.ContentType
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/chat.Chat/Login",
+	return ""
+}
+
+func (m *FileInfo) GetLength() int64 {
+	if m != nil {
+		return m.Length
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServer).Login(ctx, req.(*LoginRequest))
+	return 0
+}
+
+func (m *FileInfo) GetSha256() []byte {
+	if m != nil {
+		return m.Sha256
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _Chat_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LogoutRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+// The first request of an upload carries the file info, every following
+// one a chunk of its content.
+type UploadRequest struct {
+	// Types that are valid to be assigned to Data:
+	//	*UploadRequest_Info
+	//	*UploadRequest_Chunk
+	Data isUploadRequest_Data `protobuf_oneof:"data"`
+}
+
+func (m *UploadRequest) Reset()                    { *m = UploadRequest{} }
+func (m *UploadRequest) String() string            { return proto.CompactTextString(m) }
+func (*UploadRequest) ProtoMessage()               {}
+func (*UploadRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{16} }
+
+type isUploadRequest_Data interface {
+	isUploadRequest_Data()
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+type UploadRequest_Info struct {
+	Info *FileInfo `protobuf:"bytes,1,opt,name=info,oneof"`
+}
+type UploadRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadRequest_Info) isUploadRequest_Data()  {}
+func (*UploadRequest_Chunk) isUploadRequest_Data() {}
+
+func (m *UploadRequest) GetData() isUploadRequest_Data {
+	if m != nil {
+		return m.Data
 	}
-	if interceptor == nil {
-		return srv.(ChatServer).Logout(ctx, in)
+	return nil
+}
+
+func (m *UploadRequest) GetInfo() *FileInfo {
+	if x, ok := m.GetData().(*UploadRequest_Info); ok {
+		return x.Info
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/chat.Chat/Logout",
+	return nil
+}
+
+func (m *UploadRequest) GetChunk() []byte {
+	if x, ok := m.GetData().(*UploadRequest_Chunk); ok {
+		return x.Chunk
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServer).Logout(ctx, req.(*LogoutRequest))
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*UploadRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _UploadRequest_OneofMarshaler, _UploadRequest_OneofUnmarshaler, _UploadRequest_OneofSizer, []interface{}{
+		(*UploadRequest_Info)(nil),
+		(*UploadRequest_Chunk)(nil),
 	}
-	return interceptor(ctx, in, info, handler)
 }
 
-func _Chat_Join_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ChatServer).Join(&chatJoinServer{stream})
+func _UploadRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*UploadRequest)
+	// data
+	switch x := m.Data.(type) {
+	case *UploadRequest_Info:
+		_ = b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Info); err != nil {
+			return err
+		}
+	case *UploadRequest_Chunk:
+		_ = b.EncodeVarint(2<<3 | proto.WireBytes)
+		_ = b.EncodeRawBytes(x.Chunk)
+	case nil:
+	default:
+		return fmt.Errorf("UploadRequest.Data has unexpected type %T", x)
+	}
+	return nil
 }
 
-type Chat_JoinServer interface {
-	Send(*Envelope) error
-	Recv() (*Envelope, error)
-	grpc.ServerStream
+func _UploadRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*UploadRequest)
+	switch tag {
+	case 1: // data.info
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FileInfo)
+		err := b.DecodeMessage(msg)
+		m.Data = &UploadRequest_Info{msg}
+		return true, err
+	case 2: // data.chunk
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Data = &UploadRequest_Chunk{x}
+		return true, err
+	default:
+		return false, nil
+	}
 }
 
-type chatJoinServer struct {
-	grpc.ServerStream
+func _UploadRequest_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*UploadRequest)
+	// data
+	switch x := m.Data.(type) {
+	case *UploadRequest_Info:
+		s := proto.Size(x.Info)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *UploadRequest_Chunk:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Chunk)))
+		n += len(x.Chunk)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
 }
 
-func (x *chatJoinServer) Send(m *Envelope) error {
-	return x.ServerStream.SendMsg(m)
+type UploadResponse struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 }
 
-func (x *chatJoinServer) Recv() (*Envelope, error) {
-	m := new(Envelope)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *UploadResponse) Reset()                    { *m = UploadResponse{} }
+func (m *UploadResponse) String() string            { return proto.CompactTextString(m) }
+func (*UploadResponse) ProtoMessage()               {}
+func (*UploadResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{17} }
+
+func (m *UploadResponse) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return m, nil
+	return ""
 }
 
-var _Chat_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "chat.Chat",
-	HandlerType: (*ChatServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Login",
-			Handler:    _Chat_Login_Handler,
-		},
-		{
-			MethodName: "Logout",
-			Handler:    _Chat_Logout_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "Join",
-			Handler:       _Chat_Join_Handler,
-			ServerStreams: true,
-			ClientStreams: true,
-		},
-	},
-	Metadata: "chat.proto",
+type DownloadRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 }
 
-func (m *LoginRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *DownloadRequest) Reset()                    { *m = DownloadRequest{} }
+func (m *DownloadRequest) String() string            { return proto.CompactTextString(m) }
+func (*DownloadRequest) ProtoMessage()               {}
+func (*DownloadRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{18} }
+
+func (m *DownloadRequest) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *LoginRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Username) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
-		i += copy(dAtA[i:], m.Username)
+// The first response of a download carries the file info, every following
+// one a chunk of its content.
+type DownloadResponse struct {
+	// Types that are valid to be assigned to Data:
+	//	*DownloadResponse_Info
+	//	*DownloadResponse_Chunk
+	Data isDownloadResponse_Data `protobuf_oneof:"data"`
+}
+
+func (m *DownloadResponse) Reset()                    { *m = DownloadResponse{} }
+func (m *DownloadResponse) String() string            { return proto.CompactTextString(m) }
+func (*DownloadResponse) ProtoMessage()               {}
+func (*DownloadResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{19} }
+
+type isDownloadResponse_Data interface {
+	isDownloadResponse_Data()
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+type DownloadResponse_Info struct {
+	Info *FileInfo `protobuf:"bytes,1,opt,name=info,oneof"`
+}
+type DownloadResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*DownloadResponse_Info) isDownloadResponse_Data()  {}
+func (*DownloadResponse_Chunk) isDownloadResponse_Data() {}
+
+func (m *DownloadResponse) GetData() isDownloadResponse_Data {
+	if m != nil {
+		return m.Data
 	}
-	if len(m.ClientKey) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.ClientKey)))
-		i += copy(dAtA[i:], m.ClientKey)
+	return nil
+}
+
+func (m *DownloadResponse) GetInfo() *FileInfo {
+	if x, ok := m.GetData().(*DownloadResponse_Info); ok {
+		return x.Info
 	}
-	return i, nil
+	return nil
 }
 
-func (m *LoginResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *DownloadResponse) GetChunk() []byte {
+	if x, ok := m.GetData().(*DownloadResponse_Chunk); ok {
+		return x.Chunk
 	}
-	return dAtA[:n], nil
+	return nil
 }
 
-func (m *LoginResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ServerKey) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.ServerKey)))
-		i += copy(dAtA[i:], m.ServerKey)
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*DownloadResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _DownloadResponse_OneofMarshaler, _DownloadResponse_OneofUnmarshaler, _DownloadResponse_OneofSizer, []interface{}{
+		(*DownloadResponse_Info)(nil),
+		(*DownloadResponse_Chunk)(nil),
 	}
-	return i, nil
 }
 
-func (m *LogoutRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func _DownloadResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*DownloadResponse)
+	// data
+	switch x := m.Data.(type) {
+	case *DownloadResponse_Info:
+		_ = b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Info); err != nil {
+			return err
+		}
+	case *DownloadResponse_Chunk:
+		_ = b.EncodeVarint(2<<3 | proto.WireBytes)
+		_ = b.EncodeRawBytes(x.Chunk)
+	case nil:
+	default:
+		return fmt.Errorf("DownloadResponse.Data has unexpected type %T", x)
 	}
-	return dAtA[:n], nil
+	return nil
 }
 
-func (m *LogoutRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Username) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
-		i += copy(dAtA[i:], m.Username)
+func _DownloadResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*DownloadResponse)
+	switch tag {
+	case 1: // data.info
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FileInfo)
+		err := b.DecodeMessage(msg)
+		m.Data = &DownloadResponse_Info{msg}
+		return true, err
+	case 2: // data.chunk
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Data = &DownloadResponse_Chunk{x}
+		return true, err
+	default:
+		return false, nil
 	}
-	return i, nil
 }
 
-func (m *LogoutResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func _DownloadResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*DownloadResponse)
+	// data
+	switch x := m.Data.(type) {
+	case *DownloadResponse_Info:
+		s := proto.Size(x.Info)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *DownloadResponse_Chunk:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Chunk)))
+		n += len(x.Chunk)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
 	}
-	return dAtA[:n], nil
+	return n
 }
 
-func (m *LogoutResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
+type ServerKeyRequest struct {
 }
 
-func (m *Message) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
-	}
-	return dAtA[:n], nil
+func (m *ServerKeyRequest) Reset()                    { *m = ServerKeyRequest{} }
+func (m *ServerKeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*ServerKeyRequest) ProtoMessage()               {}
+func (*ServerKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{20} }
+
+type ServerKeyResponse struct {
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 }
 
-func (m *Message) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Sender) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.Sender)))
-		i += copy(dAtA[i:], m.Sender)
+func (m *ServerKeyResponse) Reset()                    { *m = ServerKeyResponse{} }
+func (m *ServerKeyResponse) String() string            { return proto.CompactTextString(m) }
+func (*ServerKeyResponse) ProtoMessage()               {}
+func (*ServerKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{21} }
+
+func (m *ServerKeyResponse) GetKey() []byte {
+	if m != nil {
+		return m.Key
 	}
-	if len(m.Value) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.Value)))
-		i += copy(dAtA[i:], m.Value)
+	return nil
+}
+
+type ListSessionsRequest struct {
+}
+
+func (m *ListSessionsRequest) Reset()                    { *m = ListSessionsRequest{} }
+func (m *ListSessionsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListSessionsRequest) ProtoMessage()               {}
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{22} }
+
+type SessionInfo struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Id       string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// client, or gateway for the users of the IRC gateway.
+	Kind string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Peer string `protobuf:"bytes,4,opt,name=peer,proto3" json:"peer,omitempty"`
+	// Unix time of the login, in seconds.
+	ConnectedAt     int64    `protobuf:"varint,5,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"`
+	CipherSuite     string   `protobuf:"bytes,6,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	ProtocolVersion uint32   `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	Rooms           []string `protobuf:"bytes,8,rep,name=rooms" json:"rooms,omitempty"`
+	// Messages waiting to be delivered to the session.
+	QueueDepth uint32 `protobuf:"varint,9,opt,name=queue_depth,json=queueDepth,proto3" json:"queue_depth,omitempty"`
+	// Whether the client has a Join stream open.
+	Joined bool `protobuf:"varint,10,opt,name=joined,proto3" json:"joined,omitempty"`
+}
+
+func (m *SessionInfo) Reset()                    { *m = SessionInfo{} }
+func (m *SessionInfo) String() string            { return proto.CompactTextString(m) }
+func (*SessionInfo) ProtoMessage()               {}
+func (*SessionInfo) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{23} }
+
+func (m *SessionInfo) GetUsername() string {
+	if m != nil {
+		return m.Username
 	}
-	return i, nil
+	return ""
 }
 
-func (m *Envelope) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *SessionInfo) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *Envelope) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Message) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintChat(dAtA, i, uint64(len(m.Message)))
-		i += copy(dAtA[i:], m.Message)
+func (m *SessionInfo) GetKind() string {
+	if m != nil {
+		return m.Kind
 	}
-	return i, nil
+	return ""
 }
 
-func encodeVarintChat(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *SessionInfo) GetPeer() string {
+	if m != nil {
+		return m.Peer
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return ""
 }
-func (m *LoginRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Username)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+
+func (m *SessionInfo) GetConnectedAt() int64 {
+	if m != nil {
+		return m.ConnectedAt
 	}
-	l = len(m.ClientKey)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+	return 0
+}
+
+func (m *SessionInfo) GetCipherSuite() string {
+	if m != nil {
+		return m.CipherSuite
+	}
+	return ""
+}
+
+func (m *SessionInfo) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *SessionInfo) GetRooms() []string {
+	if m != nil {
+		return m.Rooms
+	}
+	return nil
+}
+
+func (m *SessionInfo) GetQueueDepth() uint32 {
+	if m != nil {
+		return m.QueueDepth
+	}
+	return 0
+}
+
+func (m *SessionInfo) GetJoined() bool {
+	if m != nil {
+		return m.Joined
+	}
+	return false
+}
+
+type ListSessionsResponse struct {
+	Sessions []*SessionInfo `protobuf:"bytes,1,rep,name=sessions" json:"sessions,omitempty"`
+}
+
+func (m *ListSessionsResponse) Reset()                    { *m = ListSessionsResponse{} }
+func (m *ListSessionsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListSessionsResponse) ProtoMessage()               {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{24} }
+
+func (m *ListSessionsResponse) GetSessions() []*SessionInfo {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type DisconnectRequest struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// Told to the user, if set.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (m *DisconnectRequest) Reset()                    { *m = DisconnectRequest{} }
+func (m *DisconnectRequest) String() string            { return proto.CompactTextString(m) }
+func (*DisconnectRequest) ProtoMessage()               {}
+func (*DisconnectRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{25} }
+
+func (m *DisconnectRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *DisconnectRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type DisconnectResponse struct {
+}
+
+func (m *DisconnectResponse) Reset()                    { *m = DisconnectResponse{} }
+func (m *DisconnectResponse) String() string            { return proto.CompactTextString(m) }
+func (*DisconnectResponse) ProtoMessage()               {}
+func (*DisconnectResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{26} }
+
+// Announcements are system notices, signed by the server, to everyone in
+// the room, the public room if empty.
+type AnnounceRequest struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Room string `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+}
+
+func (m *AnnounceRequest) Reset()                    { *m = AnnounceRequest{} }
+func (m *AnnounceRequest) String() string            { return proto.CompactTextString(m) }
+func (*AnnounceRequest) ProtoMessage()               {}
+func (*AnnounceRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{27} }
+
+func (m *AnnounceRequest) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *AnnounceRequest) GetRoom() string {
+	if m != nil {
+		return m.Room
+	}
+	return ""
+}
+
+type AnnounceResponse struct {
+}
+
+func (m *AnnounceResponse) Reset()                    { *m = AnnounceResponse{} }
+func (m *AnnounceResponse) String() string            { return proto.CompactTextString(m) }
+func (*AnnounceResponse) ProtoMessage()               {}
+func (*AnnounceResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{28} }
+
+type StatsRequest struct {
+}
+
+func (m *StatsRequest) Reset()                    { *m = StatsRequest{} }
+func (m *StatsRequest) String() string            { return proto.CompactTextString(m) }
+func (*StatsRequest) ProtoMessage()               {}
+func (*StatsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{29} }
+
+type StatsResponse struct {
+	// Unix time the server started at, in seconds.
+	StartedAt         int64  `protobuf:"varint,1,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Clients           uint32 `protobuf:"varint,2,opt,name=clients,proto3" json:"clients,omitempty"`
+	Gateways          uint32 `protobuf:"varint,3,opt,name=gateways,proto3" json:"gateways,omitempty"`
+	Subscribers       uint32 `protobuf:"varint,4,opt,name=subscribers,proto3" json:"subscribers,omitempty"`
+	BroadcastBacklog  uint32 `protobuf:"varint,5,opt,name=broadcast_backlog,json=broadcastBacklog,proto3" json:"broadcast_backlog,omitempty"`
+	BroadcastCapacity uint32 `protobuf:"varint,6,opt,name=broadcast_capacity,json=broadcastCapacity,proto3" json:"broadcast_capacity,omitempty"`
+	Logins            uint64 `protobuf:"varint,7,opt,name=logins,proto3" json:"logins,omitempty"`
+	FailedLogins      uint64 `protobuf:"varint,8,opt,name=failed_logins,json=failedLogins,proto3" json:"failed_logins,omitempty"`
+	MessagesReceived  uint64 `protobuf:"varint,9,opt,name=messages_received,json=messagesReceived,proto3" json:"messages_received,omitempty"`
+	MessagesSent      uint64 `protobuf:"varint,10,opt,name=messages_sent,json=messagesSent,proto3" json:"messages_sent,omitempty"`
+}
+
+func (m *StatsResponse) Reset()                    { *m = StatsResponse{} }
+func (m *StatsResponse) String() string            { return proto.CompactTextString(m) }
+func (*StatsResponse) ProtoMessage()               {}
+func (*StatsResponse) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{30} }
+
+func (m *StatsResponse) GetStartedAt() int64 {
+	if m != nil {
+		return m.StartedAt
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetClients() uint32 {
+	if m != nil {
+		return m.Clients
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetGateways() uint32 {
+	if m != nil {
+		return m.Gateways
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetSubscribers() uint32 {
+	if m != nil {
+		return m.Subscribers
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetBroadcastBacklog() uint32 {
+	if m != nil {
+		return m.BroadcastBacklog
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetBroadcastCapacity() uint32 {
+	if m != nil {
+		return m.BroadcastCapacity
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetLogins() uint64 {
+	if m != nil {
+		return m.Logins
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetFailedLogins() uint64 {
+	if m != nil {
+		return m.FailedLogins
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetMessagesReceived() uint64 {
+	if m != nil {
+		return m.MessagesReceived
+	}
+	return 0
+}
+
+func (m *StatsResponse) GetMessagesSent() uint64 {
+	if m != nil {
+		return m.MessagesSent
+	}
+	return 0
+}
+
+type GetSettingsRequest struct {
+}
+
+func (m *GetSettingsRequest) Reset()                    { *m = GetSettingsRequest{} }
+func (m *GetSettingsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetSettingsRequest) ProtoMessage()               {}
+func (*GetSettingsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{31} }
+
+// Settings are the parts of the configuration of the server that can be
+// changed while it runs.
+type Settings struct {
+	// The suites clients may agree on the session key with, in order of
+	// preference.
+	CipherSuites []string `protobuf:"bytes,1,rep,name=cipher_suites,json=cipherSuites" json:"cipher_suites,omitempty"`
+	// debug, info, warn or error.
+	LogLevel      string `protobuf:"bytes,2,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	MaxUploadSize int64  `protobuf:"varint,3,opt,name=max_upload_size,json=maxUploadSize,proto3" json:"max_upload_size,omitempty"`
+}
+
+func (m *Settings) Reset()                    { *m = Settings{} }
+func (m *Settings) String() string            { return proto.CompactTextString(m) }
+func (*Settings) ProtoMessage()               {}
+func (*Settings) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{32} }
+
+func (m *Settings) GetCipherSuites() []string {
+	if m != nil {
+		return m.CipherSuites
+	}
+	return nil
+}
+
+func (m *Settings) GetLogLevel() string {
+	if m != nil {
+		return m.LogLevel
+	}
+	return ""
+}
+
+func (m *Settings) GetMaxUploadSize() int64 {
+	if m != nil {
+		return m.MaxUploadSize
+	}
+	return 0
+}
+
+type UpdateSettingsRequest struct {
+	// Fields left empty keep their current value.
+	Settings *Settings `protobuf:"bytes,1,opt,name=settings" json:"settings,omitempty"`
+}
+
+func (m *UpdateSettingsRequest) Reset()                    { *m = UpdateSettingsRequest{} }
+func (m *UpdateSettingsRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateSettingsRequest) ProtoMessage()               {}
+func (*UpdateSettingsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChat, []int{33} }
+
+func (m *UpdateSettingsRequest) GetSettings() *Settings {
+	if m != nil {
+		return m.Settings
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*LoginRequest)(nil), "chat.LoginRequest")
+	proto.RegisterType((*KeyShare)(nil), "chat.KeyShare")
+	proto.RegisterType((*LoginResponse)(nil), "chat.LoginResponse")
+	proto.RegisterType((*KeyRotation)(nil), "chat.KeyRotation")
+	proto.RegisterType((*LogoutRequest)(nil), "chat.LogoutRequest")
+	proto.RegisterType((*LogoutResponse)(nil), "chat.LogoutResponse")
+	proto.RegisterType((*UsersRequest)(nil), "chat.UsersRequest")
+	proto.RegisterType((*UsersResponse)(nil), "chat.UsersResponse")
+	proto.RegisterType((*KeysRequest)(nil), "chat.KeysRequest")
+	proto.RegisterType((*PublicKey)(nil), "chat.PublicKey")
+	proto.RegisterType((*KeysResponse)(nil), "chat.KeysResponse")
+	proto.RegisterType((*Message)(nil), "chat.Message")
+	proto.RegisterType((*Envelope)(nil), "chat.Envelope")
+	proto.RegisterType((*WrappedKey)(nil), "chat.WrappedKey")
+	proto.RegisterType((*RatchetHeader)(nil), "chat.RatchetHeader")
+	proto.RegisterType((*FileInfo)(nil), "chat.FileInfo")
+	proto.RegisterType((*UploadRequest)(nil), "chat.UploadRequest")
+	proto.RegisterType((*UploadResponse)(nil), "chat.UploadResponse")
+	proto.RegisterType((*DownloadRequest)(nil), "chat.DownloadRequest")
+	proto.RegisterType((*DownloadResponse)(nil), "chat.DownloadResponse")
+	proto.RegisterType((*ServerKeyRequest)(nil), "chat.ServerKeyRequest")
+	proto.RegisterType((*ServerKeyResponse)(nil), "chat.ServerKeyResponse")
+	proto.RegisterType((*ListSessionsRequest)(nil), "chat.ListSessionsRequest")
+	proto.RegisterType((*SessionInfo)(nil), "chat.SessionInfo")
+	proto.RegisterType((*ListSessionsResponse)(nil), "chat.ListSessionsResponse")
+	proto.RegisterType((*DisconnectRequest)(nil), "chat.DisconnectRequest")
+	proto.RegisterType((*DisconnectResponse)(nil), "chat.DisconnectResponse")
+	proto.RegisterType((*AnnounceRequest)(nil), "chat.AnnounceRequest")
+	proto.RegisterType((*AnnounceResponse)(nil), "chat.AnnounceResponse")
+	proto.RegisterType((*StatsRequest)(nil), "chat.StatsRequest")
+	proto.RegisterType((*StatsResponse)(nil), "chat.StatsResponse")
+	proto.RegisterType((*GetSettingsRequest)(nil), "chat.GetSettingsRequest")
+	proto.RegisterType((*Settings)(nil), "chat.Settings")
+	proto.RegisterType((*UpdateSettingsRequest)(nil), "chat.UpdateSettingsRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Chat service
+
+type ChatClient interface {
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error)
+	Join(ctx context.Context, opts ...grpc.CallOption) (Chat_JoinClient, error)
+	Users(ctx context.Context, in *UsersRequest, opts ...grpc.CallOption) (*UsersResponse, error)
+	Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (*KeysResponse, error)
+	Upload(ctx context.Context, opts ...grpc.CallOption) (Chat_UploadClient, error)
+	Download(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (Chat_DownloadClient, error)
+	ServerKey(ctx context.Context, in *ServerKeyRequest, opts ...grpc.CallOption) (*ServerKeyResponse, error)
+}
+
+type chatClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChatClient(cc *grpc.ClientConn) ChatClient {
+	return &chatClient{cc}
+}
+
+func (c *chatClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := grpc.Invoke(ctx, "/chat.Chat/Login", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error) {
+	out := new(LogoutResponse)
+	err := grpc.Invoke(ctx, "/chat.Chat/Logout", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) Join(ctx context.Context, opts ...grpc.CallOption) (Chat_JoinClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chat_serviceDesc.Streams[0], c.cc, "/chat.Chat/Join", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatJoinClient{stream}
+	return x, nil
+}
+
+type Chat_JoinClient interface {
+	Send(*Envelope) error
+	Recv() (*Envelope, error)
+	grpc.ClientStream
+}
+
+type chatJoinClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatJoinClient) Send(m *Envelope) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatJoinClient) Recv() (*Envelope, error) {
+	m := new(Envelope)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatClient) Users(ctx context.Context, in *UsersRequest, opts ...grpc.CallOption) (*UsersResponse, error) {
+	out := new(UsersResponse)
+	err := grpc.Invoke(ctx, "/chat.Chat/Users", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) Keys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (*KeysResponse, error) {
+	out := new(KeysResponse)
+	err := grpc.Invoke(ctx, "/chat.Chat/Keys", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) Upload(ctx context.Context, opts ...grpc.CallOption) (Chat_UploadClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chat_serviceDesc.Streams[1], c.cc, "/chat.Chat/Upload", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatUploadClient{stream}
+	return x, nil
+}
+
+type Chat_UploadClient interface {
+	Send(*UploadRequest) error
+	CloseAndRecv() (*UploadResponse, error)
+	grpc.ClientStream
+}
+
+type chatUploadClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatUploadClient) Send(m *UploadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatUploadClient) CloseAndRecv() (*UploadResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatClient) Download(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (Chat_DownloadClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chat_serviceDesc.Streams[2], c.cc, "/chat.Chat/Download", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatDownloadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Chat_DownloadClient interface {
+	Recv() (*DownloadResponse, error)
+	grpc.ClientStream
+}
+
+type chatDownloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatDownloadClient) Recv() (*DownloadResponse, error) {
+	m := new(DownloadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatClient) ServerKey(ctx context.Context, in *ServerKeyRequest, opts ...grpc.CallOption) (*ServerKeyResponse, error) {
+	out := new(ServerKeyResponse)
+	err := grpc.Invoke(ctx, "/chat.Chat/ServerKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Chat service
+
+type ChatServer interface {
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	Logout(context.Context, *LogoutRequest) (*LogoutResponse, error)
+	Join(Chat_JoinServer) error
+	Users(context.Context, *UsersRequest) (*UsersResponse, error)
+	Keys(context.Context, *KeysRequest) (*KeysResponse, error)
+	Upload(Chat_UploadServer) error
+	Download(*DownloadRequest, Chat_DownloadServer) error
+	ServerKey(context.Context, *ServerKeyRequest) (*ServerKeyResponse, error)
+}
+
+func RegisterChatServer(s *grpc.Server, srv ChatServer) {
+	s.RegisterService(&_Chat_serviceDesc, srv)
+}
+
+func _Chat_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Chat/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Chat/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).Logout(ctx, req.(*LogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_Join_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServer).Join(&chatJoinServer{stream})
+}
+
+type Chat_JoinServer interface {
+	Send(*Envelope) error
+	Recv() (*Envelope, error)
+	grpc.ServerStream
+}
+
+type chatJoinServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatJoinServer) Send(m *Envelope) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatJoinServer) Recv() (*Envelope, error) {
+	m := new(Envelope)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Chat_Users_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).Users(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Chat/Users",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).Users(ctx, req.(*UsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_Keys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).Keys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Chat/Keys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).Keys(ctx, req.(*KeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServer).Upload(&chatUploadServer{stream})
+}
+
+type Chat_UploadServer interface {
+	SendAndClose(*UploadResponse) error
+	Recv() (*UploadRequest, error)
+	grpc.ServerStream
+}
+
+type chatUploadServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatUploadServer) SendAndClose(m *UploadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatUploadServer) Recv() (*UploadRequest, error) {
+	m := new(UploadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Chat_Download_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServer).Download(m, &chatDownloadServer{stream})
+}
+
+type Chat_DownloadServer interface {
+	Send(*DownloadResponse) error
+	grpc.ServerStream
+}
+
+type chatDownloadServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatDownloadServer) Send(m *DownloadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Chat_ServerKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).ServerKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Chat/ServerKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).ServerKey(ctx, req.(*ServerKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Chat_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "chat.Chat",
+	HandlerType: (*ChatServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Login",
+			Handler:    _Chat_Login_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _Chat_Logout_Handler,
+		},
+		{
+			MethodName: "Users",
+			Handler:    _Chat_Users_Handler,
+		},
+		{
+			MethodName: "Keys",
+			Handler:    _Chat_Keys_Handler,
+		},
+		{
+			MethodName: "ServerKey",
+			Handler:    _Chat_ServerKey_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Join",
+			Handler:       _Chat_Join_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Upload",
+			Handler:       _Chat_Upload_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Download",
+			Handler:       _Chat_Download_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "chat.proto",
+}
+
+// Client API for Admin service
+
+type AdminClient interface {
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error)
+	Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	GetSettings(ctx context.Context, in *GetSettingsRequest, opts ...grpc.CallOption) (*Settings, error)
+	UpdateSettings(ctx context.Context, in *UpdateSettingsRequest, opts ...grpc.CallOption) (*Settings, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := grpc.Invoke(ctx, "/chat.Admin/ListSessions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error) {
+	out := new(DisconnectResponse)
+	err := grpc.Invoke(ctx, "/chat.Admin/Disconnect", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error) {
+	out := new(AnnounceResponse)
+	err := grpc.Invoke(ctx, "/chat.Admin/Announce", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := grpc.Invoke(ctx, "/chat.Admin/Stats", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetSettings(ctx context.Context, in *GetSettingsRequest, opts ...grpc.CallOption) (*Settings, error) {
+	out := new(Settings)
+	err := grpc.Invoke(ctx, "/chat.Admin/GetSettings", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) UpdateSettings(ctx context.Context, in *UpdateSettingsRequest, opts ...grpc.CallOption) (*Settings, error) {
+	out := new(Settings)
+	err := grpc.Invoke(ctx, "/chat.Admin/UpdateSettings", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Admin service
+
+type AdminServer interface {
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	Disconnect(context.Context, *DisconnectRequest) (*DisconnectResponse, error)
+	Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	GetSettings(context.Context, *GetSettingsRequest) (*Settings, error)
+	UpdateSettings(context.Context, *UpdateSettingsRequest) (*Settings, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Disconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/Disconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Disconnect(ctx, req.(*DisconnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnounceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Announce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/Announce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Announce(ctx, req.(*AnnounceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/GetSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetSettings(ctx, req.(*GetSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_UpdateSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UpdateSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.Admin/UpdateSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UpdateSettings(ctx, req.(*UpdateSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "chat.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSessions",
+			Handler:    _Admin_ListSessions_Handler,
+		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _Admin_Disconnect_Handler,
+		},
+		{
+			MethodName: "Announce",
+			Handler:    _Admin_Announce_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Admin_Stats_Handler,
+		},
+		{
+			MethodName: "GetSettings",
+			Handler:    _Admin_GetSettings_Handler,
+		},
+		{
+			MethodName: "UpdateSettings",
+			Handler:    _Admin_UpdateSettings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chat.proto",
+}
+
+func (m *LoginRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LoginRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Username) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
+		i += copy(dAtA[i:], m.Username)
+	}
+	if len(m.ClientKey) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.ClientKey)))
+		i += copy(dAtA[i:], m.ClientKey)
+	}
+	if len(m.EphemeralKey) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.EphemeralKey)))
+		i += copy(dAtA[i:], m.EphemeralKey)
+	}
+	if len(m.RatchetKey) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.RatchetKey)))
+		i += copy(dAtA[i:], m.RatchetKey)
+	}
+	if len(m.RatchetKeySignature) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.RatchetKeySignature)))
+		i += copy(dAtA[i:], m.RatchetKeySignature)
+	}
+	if len(m.SigningKey) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.SigningKey)))
+		i += copy(dAtA[i:], m.SigningKey)
+	}
+	if m.ProtocolVersion != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.ProtocolVersion))
+	}
+	if len(m.KeyShares) > 0 {
+		for _, msg := range m.KeyShares {
+			dAtA[i] = 0x42
+			i++
+			i = encodeVarintChat(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *KeyShare) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeyShare) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.CipherSuite) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.CipherSuite)))
+		i += copy(dAtA[i:], m.CipherSuite)
+	}
+	if len(m.Key) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	return i, nil
+}
+
+func (m *LoginResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LoginResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ServerKey) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.ServerKey)))
+		i += copy(dAtA[i:], m.ServerKey)
+	}
+	if len(m.EphemeralKey) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.EphemeralKey)))
+		i += copy(dAtA[i:], m.EphemeralKey)
+	}
+	if len(m.EphemeralKeySignature) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.EphemeralKeySignature)))
+		i += copy(dAtA[i:], m.EphemeralKeySignature)
+	}
+	if m.ProtocolVersion != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.ProtocolVersion))
+	}
+	if len(m.CipherSuite) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.CipherSuite)))
+		i += copy(dAtA[i:], m.CipherSuite)
+	}
+	if len(m.KeyRotations) > 0 {
+		for _, msg := range m.KeyRotations {
+			dAtA[i] = 0x32
+			i++
+			i = encodeVarintChat(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *KeyRotation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeyRotation) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.PreviousKey) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.PreviousKey)))
+		i += copy(dAtA[i:], m.PreviousKey)
+	}
+	if len(m.Key) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	return i, nil
+}
+
+func (m *LogoutRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LogoutRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Username) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
+		i += copy(dAtA[i:], m.Username)
+	}
+	return i, nil
+}
+
+func (m *LogoutResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LogoutResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *UsersRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UsersRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *UsersResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UsersResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Usernames) > 0 {
+		for _, s := range m.Usernames {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *KeysRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeysRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *PublicKey) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PublicKey) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Username) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
+		i += copy(dAtA[i:], m.Username)
+	}
+	if len(m.Key) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.Gateway {
+		dAtA[i] = 0x18
+		i++
+		if m.Gateway {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.RatchetKey) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.RatchetKey)))
+		i += copy(dAtA[i:], m.RatchetKey)
+	}
+	if len(m.RatchetKeySignature) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.RatchetKeySignature)))
+		i += copy(dAtA[i:], m.RatchetKeySignature)
+	}
+	if len(m.SigningKey) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.SigningKey)))
+		i += copy(dAtA[i:], m.SigningKey)
+	}
+	return i, nil
+}
+
+func (m *KeysResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeysResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, msg := range m.Keys {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintChat(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *Message) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Message) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Sender) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Sender)))
+		i += copy(dAtA[i:], m.Sender)
+	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if len(m.ServerKey) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.ServerKey)))
+		i += copy(dAtA[i:], m.ServerKey)
+	}
+	return i, nil
+}
+
+func (m *Envelope) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Envelope) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Message) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if len(m.Room) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Room)))
+		i += copy(dAtA[i:], m.Room)
+	}
+	if len(m.Keys) > 0 {
+		for k, _ := range m.Keys {
+			dAtA[i] = 0x1a
+			i++
+			v := m.Keys[k]
+			msgSize := 0
+			if v != nil {
+				msgSize = v.Size()
+				msgSize += 1 + sovChat(uint64(msgSize))
+			}
+			mapSize := 1 + len(k) + sovChat(uint64(len(k))) + msgSize
+			i = encodeVarintChat(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintChat(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			if v != nil {
+				dAtA[i] = 0x12
+				i++
+				i = encodeVarintChat(dAtA, i, uint64(v.Size()))
+				n1, err := v.MarshalTo(dAtA[i:])
+				if err != nil {
+					return 0, err
+				}
+				i += n1
+			}
+		}
+	}
+	if len(m.Sender) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Sender)))
+		i += copy(dAtA[i:], m.Sender)
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if len(m.Stamp) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Stamp)))
+		i += copy(dAtA[i:], m.Stamp)
+	}
+	return i, nil
+}
+
+func (m *WrappedKey) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WrappedKey) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.Header != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Header.Size()))
+		n2, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	return i, nil
+}
+
+func (m *RatchetHeader) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RatchetHeader) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Session) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Session)))
+		i += copy(dAtA[i:], m.Session)
+	}
+	if len(m.RecipientKey) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.RecipientKey)))
+		i += copy(dAtA[i:], m.RecipientKey)
+	}
+	if len(m.PublicKey) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.PublicKey)))
+		i += copy(dAtA[i:], m.PublicKey)
+	}
+	if m.PreviousLength != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.PreviousLength))
+	}
+	if m.Number != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Number))
+	}
+	return i, nil
+}
+
+func (m *FileInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FileInfo) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.ContentType) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.ContentType)))
+		i += copy(dAtA[i:], m.ContentType)
+	}
+	if m.Length != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Length))
+	}
+	if len(m.Sha256) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Sha256)))
+		i += copy(dAtA[i:], m.Sha256)
+	}
+	return i, nil
+}
+
+func (m *UploadRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UploadRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Data != nil {
+		nn3, err := m.Data.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += nn3
+	}
+	return i, nil
+}
+
+func (m *UploadRequest_Info) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Info != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Info.Size()))
+		n4, err := m.Info.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	return i, nil
+}
+func (m *UploadRequest_Chunk) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Chunk != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Chunk)))
+		i += copy(dAtA[i:], m.Chunk)
+	}
+	return i, nil
+}
+func (m *UploadResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UploadResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Id) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Id)))
+		i += copy(dAtA[i:], m.Id)
+	}
+	return i, nil
+}
+
+func (m *DownloadRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DownloadRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Id) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Id)))
+		i += copy(dAtA[i:], m.Id)
+	}
+	return i, nil
+}
+
+func (m *DownloadResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DownloadResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Data != nil {
+		nn5, err := m.Data.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += nn5
+	}
+	return i, nil
+}
+
+func (m *DownloadResponse_Info) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Info != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Info.Size()))
+		n6, err := m.Info.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n6
+	}
+	return i, nil
+}
+func (m *DownloadResponse_Chunk) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Chunk != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Chunk)))
+		i += copy(dAtA[i:], m.Chunk)
+	}
+	return i, nil
+}
+func (m *ServerKeyRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ServerKeyRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *ServerKeyResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ServerKeyResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	return i, nil
+}
+
+func (m *ListSessionsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListSessionsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *SessionInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SessionInfo) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Username) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
+		i += copy(dAtA[i:], m.Username)
+	}
+	if len(m.Id) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Id)))
+		i += copy(dAtA[i:], m.Id)
+	}
+	if len(m.Kind) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Kind)))
+		i += copy(dAtA[i:], m.Kind)
+	}
+	if len(m.Peer) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Peer)))
+		i += copy(dAtA[i:], m.Peer)
+	}
+	if m.ConnectedAt != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.ConnectedAt))
+	}
+	if len(m.CipherSuite) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.CipherSuite)))
+		i += copy(dAtA[i:], m.CipherSuite)
+	}
+	if m.ProtocolVersion != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.ProtocolVersion))
+	}
+	if len(m.Rooms) > 0 {
+		for _, s := range m.Rooms {
+			dAtA[i] = 0x42
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.QueueDepth != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.QueueDepth))
+	}
+	if m.Joined {
+		dAtA[i] = 0x50
+		i++
+		if m.Joined {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *ListSessionsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListSessionsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Sessions) > 0 {
+		for _, msg := range m.Sessions {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintChat(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *DisconnectRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DisconnectRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Username) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Username)))
+		i += copy(dAtA[i:], m.Username)
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	return i, nil
+}
+
+func (m *DisconnectResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DisconnectResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *AnnounceRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AnnounceRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Text) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Text)))
+		i += copy(dAtA[i:], m.Text)
+	}
+	if len(m.Room) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.Room)))
+		i += copy(dAtA[i:], m.Room)
+	}
+	return i, nil
+}
+
+func (m *AnnounceResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AnnounceResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *StatsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StatsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *StatsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StatsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.StartedAt != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.StartedAt))
+	}
+	if m.Clients != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Clients))
+	}
+	if m.Gateways != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Gateways))
+	}
+	if m.Subscribers != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Subscribers))
+	}
+	if m.BroadcastBacklog != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.BroadcastBacklog))
+	}
+	if m.BroadcastCapacity != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.BroadcastCapacity))
+	}
+	if m.Logins != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Logins))
+	}
+	if m.FailedLogins != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.FailedLogins))
+	}
+	if m.MessagesReceived != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.MessagesReceived))
+	}
+	if m.MessagesSent != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.MessagesSent))
+	}
+	return i, nil
+}
+
+func (m *GetSettingsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetSettingsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *Settings) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Settings) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.CipherSuites) > 0 {
+		for _, s := range m.CipherSuites {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.LogLevel) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(len(m.LogLevel)))
+		i += copy(dAtA[i:], m.LogLevel)
+	}
+	if m.MaxUploadSize != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.MaxUploadSize))
+	}
+	return i, nil
+}
+
+func (m *UpdateSettingsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UpdateSettingsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Settings != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChat(dAtA, i, uint64(m.Settings.Size()))
+		n7, err := m.Settings.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
+	}
+	return i, nil
+}
+
+func encodeVarintChat(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *LoginRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.ClientKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.EphemeralKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.RatchetKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.RatchetKeySignature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.SigningKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.ProtocolVersion != 0 {
+		n += 1 + sovChat(uint64(m.ProtocolVersion))
+	}
+	if len(m.KeyShares) > 0 {
+		for _, e := range m.KeyShares {
+			l = e.Size()
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *KeyShare) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.CipherSuite)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *LoginResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ServerKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.EphemeralKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.EphemeralKeySignature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.ProtocolVersion != 0 {
+		n += 1 + sovChat(uint64(m.ProtocolVersion))
+	}
+	l = len(m.CipherSuite)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if len(m.KeyRotations) > 0 {
+		for _, e := range m.KeyRotations {
+			l = e.Size()
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *KeyRotation) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.PreviousKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *LogoutRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *LogoutResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *UsersRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *UsersResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Usernames) > 0 {
+		for _, s := range m.Usernames {
+			l = len(s)
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *KeysRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PublicKey) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.Gateway {
+		n += 2
+	}
+	l = len(m.RatchetKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.RatchetKeySignature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.SigningKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *KeysResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, e := range m.Keys {
+			l = e.Size()
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Message) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Sender)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.ServerKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *Envelope) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Room)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if len(m.Keys) > 0 {
+		for k, v := range m.Keys {
+			_ = k
+			_ = v
+			l = 0
+			if v != nil {
+				l = v.Size()
+				l += 1 + sovChat(uint64(l))
+			}
+			mapEntrySize := 1 + len(k) + sovChat(uint64(len(k))) + l
+			n += mapEntrySize + 1 + sovChat(uint64(mapEntrySize))
+		}
+	}
+	l = len(m.Sender)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Stamp)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *WrappedKey) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *RatchetHeader) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Session)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.RecipientKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.PublicKey)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.PreviousLength != 0 {
+		n += 1 + sovChat(uint64(m.PreviousLength))
+	}
+	if m.Number != 0 {
+		n += 1 + sovChat(uint64(m.Number))
+	}
+	return n
+}
+
+func (m *FileInfo) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.ContentType)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.Length != 0 {
+		n += 1 + sovChat(uint64(m.Length))
+	}
+	l = len(m.Sha256)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *UploadRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Data != nil {
+		n += m.Data.Size()
+	}
+	return n
+}
+
+func (m *UploadRequest_Info) Size() (n int) {
+	var l int
+	_ = l
+	if m.Info != nil {
+		l = m.Info.Size()
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+func (m *UploadRequest_Chunk) Size() (n int) {
+	var l int
+	_ = l
+	if m.Chunk != nil {
+		l = len(m.Chunk)
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+func (m *UploadResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *DownloadRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *DownloadResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Data != nil {
+		n += m.Data.Size()
+	}
+	return n
+}
+
+func (m *DownloadResponse_Info) Size() (n int) {
+	var l int
+	_ = l
+	if m.Info != nil {
+		l = m.Info.Size()
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+func (m *DownloadResponse_Chunk) Size() (n int) {
+	var l int
+	_ = l
+	if m.Chunk != nil {
+		l = len(m.Chunk)
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+func (m *ServerKeyRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *ServerKeyResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *ListSessionsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *SessionInfo) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Kind)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Peer)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.ConnectedAt != 0 {
+		n += 1 + sovChat(uint64(m.ConnectedAt))
+	}
+	l = len(m.CipherSuite)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.ProtocolVersion != 0 {
+		n += 1 + sovChat(uint64(m.ProtocolVersion))
+	}
+	if len(m.Rooms) > 0 {
+		for _, s := range m.Rooms {
+			l = len(s)
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	if m.QueueDepth != 0 {
+		n += 1 + sovChat(uint64(m.QueueDepth))
+	}
+	if m.Joined {
+		n += 2
+	}
+	return n
+}
+
+func (m *ListSessionsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Sessions) > 0 {
+		for _, e := range m.Sessions {
+			l = e.Size()
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DisconnectRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *DisconnectResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *AnnounceRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Text)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	l = len(m.Room)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func (m *AnnounceResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *StatsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *StatsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.StartedAt != 0 {
+		n += 1 + sovChat(uint64(m.StartedAt))
+	}
+	if m.Clients != 0 {
+		n += 1 + sovChat(uint64(m.Clients))
+	}
+	if m.Gateways != 0 {
+		n += 1 + sovChat(uint64(m.Gateways))
+	}
+	if m.Subscribers != 0 {
+		n += 1 + sovChat(uint64(m.Subscribers))
+	}
+	if m.BroadcastBacklog != 0 {
+		n += 1 + sovChat(uint64(m.BroadcastBacklog))
+	}
+	if m.BroadcastCapacity != 0 {
+		n += 1 + sovChat(uint64(m.BroadcastCapacity))
+	}
+	if m.Logins != 0 {
+		n += 1 + sovChat(uint64(m.Logins))
+	}
+	if m.FailedLogins != 0 {
+		n += 1 + sovChat(uint64(m.FailedLogins))
+	}
+	if m.MessagesReceived != 0 {
+		n += 1 + sovChat(uint64(m.MessagesReceived))
+	}
+	if m.MessagesSent != 0 {
+		n += 1 + sovChat(uint64(m.MessagesSent))
+	}
+	return n
+}
+
+func (m *GetSettingsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *Settings) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.CipherSuites) > 0 {
+		for _, s := range m.CipherSuites {
+			l = len(s)
+			n += 1 + l + sovChat(uint64(l))
+		}
+	}
+	l = len(m.LogLevel)
+	if l > 0 {
+		n += 1 + l + sovChat(uint64(l))
+	}
+	if m.MaxUploadSize != 0 {
+		n += 1 + sovChat(uint64(m.MaxUploadSize))
+	}
+	return n
+}
+
+func (m *UpdateSettingsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Settings != nil {
+		l = m.Settings.Size()
+		n += 1 + l + sovChat(uint64(l))
+	}
+	return n
+}
+
+func sovChat(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozChat(x uint64) (n int) {
+	return sovChat(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *LoginRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LoginRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LoginRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientKey = append(m.ClientKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.ClientKey == nil {
+				m.ClientKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EphemeralKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EphemeralKey = append(m.EphemeralKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.EphemeralKey == nil {
+				m.EphemeralKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RatchetKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RatchetKey = append(m.RatchetKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.RatchetKey == nil {
+				m.RatchetKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RatchetKeySignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RatchetKeySignature = append(m.RatchetKeySignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.RatchetKeySignature == nil {
+				m.RatchetKeySignature = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SigningKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SigningKey = append(m.SigningKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.SigningKey == nil {
+				m.SigningKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolVersion", wireType)
+			}
+			m.ProtocolVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProtocolVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyShares", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyShares = append(m.KeyShares, &KeyShare{})
+			if err := m.KeyShares[len(m.KeyShares)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeyShare) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyShare: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyShare: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CipherSuite", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CipherSuite = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LoginResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LoginResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LoginResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServerKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ServerKey = append(m.ServerKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.ServerKey == nil {
+				m.ServerKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EphemeralKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EphemeralKey = append(m.EphemeralKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.EphemeralKey == nil {
+				m.EphemeralKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EphemeralKeySignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EphemeralKeySignature = append(m.EphemeralKeySignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.EphemeralKeySignature == nil {
+				m.EphemeralKeySignature = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolVersion", wireType)
+			}
+			m.ProtocolVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProtocolVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CipherSuite", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CipherSuite = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyRotations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyRotations = append(m.KeyRotations, &KeyRotation{})
+			if err := m.KeyRotations[len(m.KeyRotations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeyRotation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyRotation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyRotation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PreviousKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PreviousKey = append(m.PreviousKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.PreviousKey == nil {
+				m.PreviousKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LogoutRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogoutRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogoutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LogoutResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogoutResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogoutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *UsersRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UsersRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UsersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *UsersResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UsersResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UsersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Usernames", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Usernames = append(m.Usernames, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeysRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeysRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeysRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PublicKey) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PublicKey: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PublicKey: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Gateway", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Gateway = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RatchetKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RatchetKey = append(m.RatchetKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.RatchetKey == nil {
+				m.RatchetKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RatchetKeySignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RatchetKeySignature = append(m.RatchetKeySignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.RatchetKeySignature == nil {
+				m.RatchetKeySignature = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SigningKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SigningKey = append(m.SigningKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.SigningKey == nil {
+				m.SigningKey = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeysResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeysResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeysResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, &PublicKey{})
+			if err := m.Keys[len(m.Keys)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Message) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Message: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Message: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sender = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServerKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ServerKey = append(m.ServerKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.ServerKey == nil {
+				m.ServerKey = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Envelope) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Envelope: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Envelope: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = append(m.Message[:0], dAtA[iNdEx:postIndex]...)
+			if m.Message == nil {
+				m.Message = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Room", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Room = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Keys == nil {
+				m.Keys = make(map[string]*WrappedKey)
+			}
+			var mapkey string
+			var mapvalue *WrappedKey
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowChat
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowChat
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthChat
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapmsglen int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowChat
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						mapmsglen |= (int(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					if mapmsglen < 0 {
+						return ErrInvalidLengthChat
+					}
+					postmsgIndex := iNdEx + mapmsglen
+					if mapmsglen < 0 {
+						return ErrInvalidLengthChat
+					}
+					if postmsgIndex > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = &WrappedKey{}
+					if err := mapvalue.Unmarshal(dAtA[iNdEx:postmsgIndex]); err != nil {
+						return err
+					}
+					iNdEx = postmsgIndex
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipChat(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthChat
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Keys[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sender = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stamp", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Stamp = append(m.Stamp[:0], dAtA[iNdEx:postIndex]...)
+			if m.Stamp == nil {
+				m.Stamp = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WrappedKey) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WrappedKey: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WrappedKey: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &RatchetHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RatchetHeader) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RatchetHeader: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RatchetHeader: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Session", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Session = append(m.Session[:0], dAtA[iNdEx:postIndex]...)
+			if m.Session == nil {
+				m.Session = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecipientKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RecipientKey = append(m.RecipientKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.RecipientKey == nil {
+				m.RecipientKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PublicKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PublicKey = append(m.PublicKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.PublicKey == nil {
+				m.PublicKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PreviousLength", wireType)
+			}
+			m.PreviousLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PreviousLength |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Number", wireType)
+			}
+			m.Number = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Number |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FileInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FileInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FileInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ContentType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ContentType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Length", wireType)
+			}
+			m.Length = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Length |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sha256", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sha256 = append(m.Sha256[:0], dAtA[iNdEx:postIndex]...)
+			if m.Sha256 == nil {
+				m.Sha256 = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *UploadRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UploadRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UploadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Info", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &FileInfo{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Data = &UploadRequest_Info{v}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Chunk", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Data = &UploadRequest_Chunk{v}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *UploadResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UploadResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UploadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DownloadRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DownloadRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DownloadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DownloadResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DownloadResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DownloadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Info", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &FileInfo{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Data = &DownloadResponse_Info{v}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Chunk", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Data = &DownloadResponse_Chunk{v}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ServerKeyRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ServerKeyRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ServerKeyRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ServerKeyResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ServerKeyResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ServerKeyResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListSessionsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListSessionsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListSessionsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SessionInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SessionInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SessionInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Kind", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Kind = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Peer", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Peer = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectedAt", wireType)
+			}
+			m.ConnectedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ConnectedAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CipherSuite", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CipherSuite = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolVersion", wireType)
+			}
+			m.ProtocolVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProtocolVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rooms", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rooms = append(m.Rooms, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueueDepth", wireType)
+			}
+			m.QueueDepth = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueueDepth |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Joined", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Joined = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListSessionsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListSessionsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListSessionsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sessions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sessions = append(m.Sessions, &SessionInfo{})
+			if err := m.Sessions[len(m.Sessions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *LoginResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.ServerKey)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
+	return nil
 }
-
-func (m *LogoutRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Username)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+func (m *DisconnectRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DisconnectRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DisconnectRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *LogoutResponse) Size() (n int) {
-	var l int
-	_ = l
-	return n
-}
-
-func (m *Message) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Sender)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
-	}
-	l = len(m.Value)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
+	return nil
 }
-
-func (m *Envelope) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Message)
-	if l > 0 {
-		n += 1 + l + sovChat(uint64(l))
+func (m *DisconnectResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DisconnectResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DisconnectResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func sovChat(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozChat(x uint64) (n int) {
-	return sovChat(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *LoginRequest) Unmarshal(dAtA []byte) error {
+func (m *AnnounceRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -582,15 +7202,15 @@ func (m *LoginRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LoginRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: AnnounceRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LoginRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AnnounceRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Text", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -615,13 +7235,13 @@ func (m *LoginRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Username = string(dAtA[iNdEx:postIndex])
+			m.Text = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ClientKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Room", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowChat
@@ -631,22 +7251,20 @@ func (m *LoginRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthChat
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ClientKey = append(m.ClientKey[:0], dAtA[iNdEx:postIndex]...)
-			if m.ClientKey == nil {
-				m.ClientKey = []byte{}
-			}
+			m.Room = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -669,7 +7287,7 @@ func (m *LoginRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LoginResponse) Unmarshal(dAtA []byte) error {
+func (m *AnnounceResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -692,43 +7310,62 @@ func (m *LoginResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LoginResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: AnnounceResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LoginResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AnnounceResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ServerKey", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowChat
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChat(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			if byteLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthChat
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ServerKey = append(m.ServerKey[:0], dAtA[iNdEx:postIndex]...)
-			if m.ServerKey == nil {
-				m.ServerKey = []byte{}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StatsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChat
 			}
-			iNdEx = postIndex
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StatsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StatsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
 		default:
 			iNdEx = preIndex
 			skippy, err := skipChat(dAtA[iNdEx:])
@@ -750,7 +7387,7 @@ func (m *LoginResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LogoutRequest) Unmarshal(dAtA []byte) error {
+func (m *StatsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -773,17 +7410,17 @@ func (m *LogoutRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LogoutRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: StatsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LogoutRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: StatsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartedAt", wireType)
 			}
-			var stringLen uint64
+			m.StartedAt = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowChat
@@ -793,21 +7430,182 @@ func (m *LogoutRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.StartedAt |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthChat
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Clients", wireType)
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			m.Clients = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Clients |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Gateways", wireType)
+			}
+			m.Gateways = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Gateways |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Subscribers", wireType)
+			}
+			m.Subscribers = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Subscribers |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BroadcastBacklog", wireType)
+			}
+			m.BroadcastBacklog = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BroadcastBacklog |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BroadcastCapacity", wireType)
+			}
+			m.BroadcastCapacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BroadcastCapacity |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Logins", wireType)
+			}
+			m.Logins = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Logins |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FailedLogins", wireType)
+			}
+			m.FailedLogins = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FailedLogins |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MessagesReceived", wireType)
+			}
+			m.MessagesReceived = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MessagesReceived |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MessagesSent", wireType)
+			}
+			m.MessagesSent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MessagesSent |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			m.Username = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipChat(dAtA[iNdEx:])
@@ -829,7 +7627,7 @@ func (m *LogoutRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LogoutResponse) Unmarshal(dAtA []byte) error {
+func (m *GetSettingsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -852,10 +7650,10 @@ func (m *LogoutResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LogoutResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetSettingsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LogoutResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetSettingsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		default:
@@ -879,7 +7677,7 @@ func (m *LogoutResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Message) Unmarshal(dAtA []byte) error {
+func (m *Settings) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -902,15 +7700,15 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Message: wiretype end group for non-group")
+			return fmt.Errorf("proto: Settings: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Message: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Settings: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field CipherSuites", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -935,11 +7733,11 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Sender = string(dAtA[iNdEx:postIndex])
+			m.CipherSuites = append(m.CipherSuites, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogLevel", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -964,8 +7762,27 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Value = string(dAtA[iNdEx:postIndex])
+			m.LogLevel = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxUploadSize", wireType)
+			}
+			m.MaxUploadSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxUploadSize |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipChat(dAtA[iNdEx:])
@@ -987,7 +7804,7 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Envelope) Unmarshal(dAtA []byte) error {
+func (m *UpdateSettingsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1010,17 +7827,17 @@ func (m *Envelope) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Envelope: wiretype end group for non-group")
+			return fmt.Errorf("proto: UpdateSettingsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Envelope: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: UpdateSettingsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Settings", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowChat
@@ -1030,21 +7847,23 @@ func (m *Envelope) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthChat
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Message = append(m.Message[:0], dAtA[iNdEx:postIndex]...)
-			if m.Message == nil {
-				m.Message = []byte{}
+			if m.Settings == nil {
+				m.Settings = &Settings{}
+			}
+			if err := m.Settings.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		default:
@@ -1176,24 +7995,106 @@ var (
 func init() { proto.RegisterFile("chat.proto", fileDescriptorChat) }
 
 var fileDescriptorChat = []byte{
-	// 295 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xcf, 0x4a, 0xc3, 0x40,
-	0x10, 0xc6, 0xbb, 0xd2, 0x7f, 0x19, 0x62, 0x91, 0xb1, 0x48, 0x08, 0x18, 0xca, 0xe2, 0x21, 0xa0,
-	0x04, 0xa9, 0x88, 0x77, 0xc5, 0x83, 0xff, 0x2e, 0x79, 0x01, 0x89, 0x75, 0x68, 0x83, 0xe9, 0x6e,
-	0xcc, 0x6e, 0x02, 0x7d, 0x1a, 0x5f, 0xc7, 0xa3, 0x8f, 0x20, 0x79, 0x12, 0xe9, 0x6e, 0xd2, 0x5a,
-	0x4f, 0xde, 0xf6, 0xfb, 0xf6, 0x37, 0xb3, 0xdf, 0xcc, 0x02, 0xcc, 0x16, 0x89, 0x8e, 0xf2, 0x42,
-	0x6a, 0x89, 0xdd, 0xf5, 0x99, 0xdf, 0x81, 0xfb, 0x28, 0xe7, 0xa9, 0x88, 0xe9, 0xbd, 0x24, 0xa5,
-	0xd1, 0x87, 0x61, 0xa9, 0xa8, 0x10, 0xc9, 0x92, 0x3c, 0x36, 0x61, 0xa1, 0x13, 0x6f, 0x34, 0x1e,
-	0x03, 0xcc, 0xb2, 0x94, 0x84, 0x7e, 0x7e, 0xa3, 0x95, 0xb7, 0x37, 0x61, 0xa1, 0x1b, 0x3b, 0xd6,
-	0x79, 0xa0, 0x15, 0x8f, 0x60, 0xbf, 0x69, 0xa5, 0x72, 0x29, 0x94, 0xe1, 0x15, 0x15, 0x15, 0x15,
-	0x86, 0x67, 0x96, 0xb7, 0xce, 0x9a, 0x3f, 0x35, 0xbc, 0x2c, 0xf5, 0x3f, 0xde, 0xe6, 0x07, 0x30,
-	0x6a, 0x61, 0xdb, 0x9d, 0x5f, 0xc1, 0xe0, 0x89, 0x94, 0x4a, 0xe6, 0x84, 0x47, 0xd0, 0x57, 0x24,
-	0x5e, 0xa9, 0x68, 0xca, 0x1a, 0x85, 0x63, 0xe8, 0x55, 0x49, 0x56, 0x92, 0xc9, 0xea, 0xc4, 0x56,
-	0xf0, 0x13, 0x18, 0xde, 0x8a, 0x8a, 0x32, 0x99, 0x13, 0x7a, 0x30, 0x58, 0xda, 0x26, 0x4d, 0xbe,
-	0x56, 0x4e, 0x3f, 0x18, 0x74, 0x6f, 0x16, 0x89, 0xc6, 0x29, 0xf4, 0xcc, 0x58, 0x88, 0x91, 0xd9,
-	0xde, 0xef, 0x75, 0xf9, 0x87, 0x3b, 0x5e, 0x93, 0xac, 0x83, 0x97, 0xd0, 0xb7, 0x69, 0x71, 0x0b,
-	0x6c, 0x07, 0xf5, 0xc7, 0xbb, 0xe6, 0xa6, 0xec, 0x0c, 0xba, 0xf7, 0x32, 0x15, 0x38, 0xb2, 0xf7,
-	0x6d, 0x4a, 0xff, 0x8f, 0xe6, 0x9d, 0x90, 0x9d, 0xb3, 0x6b, 0xf7, 0xb3, 0x0e, 0xd8, 0x57, 0x1d,
-	0xb0, 0xef, 0x3a, 0x60, 0x2f, 0x7d, 0xf3, 0xab, 0x17, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xf4,
-	0x0a, 0xb1, 0xfa, 0xe3, 0x01, 0x00, 0x00,
+	// 1605 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0xeb, 0x6e, 0xdb, 0x46,
+	0x16, 0x36, 0x25, 0x4a, 0x11, 0x8f, 0x2e, 0x96, 0xc7, 0x97, 0x68, 0x95, 0xcb, 0x3a, 0xcc, 0x6e,
+	0xd6, 0xbb, 0x8e, 0xb3, 0x81, 0x82, 0x64, 0x37, 0xbb, 0x68, 0x03, 0xe7, 0xd2, 0x24, 0x8d, 0x0b,
+	0x14, 0x54, 0xdd, 0xf6, 0x9f, 0x3a, 0xa6, 0x4e, 0x24, 0xd6, 0x12, 0xc9, 0x70, 0x46, 0x4e, 0x9c,
+	0x5f, 0x7d, 0x85, 0x3e, 0x42, 0x5f, 0xa0, 0x4f, 0x50, 0xf4, 0x77, 0xfb, 0xaf, 0x8f, 0x50, 0xe4,
+	0x0d, 0xfa, 0x00, 0x05, 0x8a, 0xb9, 0x92, 0x92, 0x8c, 0x20, 0x05, 0x0a, 0xf4, 0x1f, 0xcf, 0x37,
+	0x67, 0xce, 0xcc, 0xf9, 0xce, 0x6d, 0x08, 0x10, 0x8e, 0x29, 0xbf, 0x91, 0x66, 0x09, 0x4f, 0x88,
+	0x2b, 0xbe, 0xfd, 0xef, 0x4b, 0xd0, 0x38, 0x48, 0x46, 0x51, 0x1c, 0xe0, 0x8b, 0x19, 0x32, 0x4e,
+	0xba, 0x50, 0x9b, 0x31, 0xcc, 0x62, 0x3a, 0xc5, 0x8e, 0xb3, 0xed, 0xec, 0x78, 0x81, 0x95, 0xc9,
+	0x25, 0x80, 0x70, 0x12, 0x61, 0xcc, 0x07, 0xc7, 0x78, 0xda, 0x29, 0x6d, 0x3b, 0x3b, 0x8d, 0xc0,
+	0x53, 0xc8, 0x33, 0x3c, 0x25, 0x57, 0xa1, 0x89, 0xe9, 0x18, 0xa7, 0x98, 0xd1, 0x89, 0xd4, 0x28,
+	0x4b, 0x8d, 0x86, 0x05, 0x85, 0xd2, 0x5f, 0xa1, 0x9e, 0x51, 0x1e, 0x8e, 0x51, 0x19, 0x71, 0xa5,
+	0x0a, 0x68, 0x48, 0x28, 0xf4, 0x60, 0xb3, 0xa0, 0x30, 0x60, 0xd1, 0x28, 0xa6, 0x7c, 0x96, 0x61,
+	0xa7, 0x22, 0x55, 0xd7, 0x73, 0xd5, 0xbe, 0x59, 0x12, 0x46, 0x85, 0x5e, 0x14, 0x8f, 0xa4, 0xd1,
+	0xaa, 0x32, 0xaa, 0x21, 0x61, 0xf4, 0x9f, 0xd0, 0x96, 0x5e, 0x87, 0xc9, 0x64, 0x70, 0x82, 0x19,
+	0x8b, 0x92, 0xb8, 0x73, 0x6e, 0xdb, 0xd9, 0x69, 0x06, 0xab, 0x06, 0xff, 0x54, 0xc1, 0x64, 0x0f,
+	0x40, 0x9e, 0x3b, 0xa6, 0x19, 0xb2, 0x4e, 0x6d, 0xbb, 0xbc, 0x53, 0xef, 0xb5, 0x6e, 0x48, 0xe2,
+	0xc4, 0x99, 0x02, 0x0e, 0xbc, 0x63, 0xfd, 0xc5, 0xfc, 0x7b, 0x50, 0x33, 0x30, 0xb9, 0x02, 0x8d,
+	0x30, 0x4a, 0xc7, 0x98, 0x0d, 0xd8, 0x2c, 0xe2, 0x86, 0xbf, 0xba, 0xc2, 0xfa, 0x02, 0x22, 0x6d,
+	0x28, 0xe7, 0xdc, 0x89, 0x4f, 0xff, 0xeb, 0x12, 0x34, 0x75, 0x04, 0x58, 0x9a, 0xc4, 0x4c, 0xd2,
+	0xcc, 0x30, 0x3b, 0xc1, 0x4c, 0x3a, 0xe3, 0x28, 0x9a, 0x15, 0x72, 0x26, 0xcd, 0xa5, 0x33, 0x68,
+	0xbe, 0x03, 0xe7, 0xe7, 0x94, 0x0a, 0x3c, 0xaa, 0xa8, 0x6c, 0x16, 0xd5, 0x73, 0x26, 0xcf, 0x22,
+	0xca, 0x3d, 0x9b, 0xa8, 0x45, 0x6f, 0x2b, 0xcb, 0xde, 0xde, 0x81, 0xa6, 0x38, 0x3b, 0x4b, 0x38,
+	0xe5, 0x51, 0x12, 0xb3, 0x4e, 0x55, 0xd2, 0xb9, 0x66, 0xe9, 0x0c, 0xf4, 0x4a, 0xd0, 0x38, 0xce,
+	0x05, 0xe6, 0x7f, 0x01, 0xf5, 0xc2, 0xa2, 0x38, 0x29, 0xcd, 0xf0, 0x24, 0x4a, 0x66, 0xac, 0x40,
+	0x49, 0xdd, 0x60, 0xc2, 0xdf, 0x25, 0x5e, 0xc9, 0x45, 0xf0, 0x16, 0x7d, 0xce, 0x01, 0x7f, 0x57,
+	0x92, 0x9e, 0xcc, 0xf8, 0x3b, 0xe4, 0xbd, 0xdf, 0x86, 0x96, 0x51, 0x56, 0x21, 0xf2, 0x5b, 0xd0,
+	0x38, 0x64, 0x98, 0x31, 0xbd, 0xdb, 0xdf, 0x83, 0xa6, 0x96, 0x75, 0x0c, 0x2f, 0x82, 0x67, 0xb6,
+	0xb3, 0x8e, 0xb3, 0x5d, 0xde, 0xf1, 0x82, 0x1c, 0xf0, 0x9b, 0xd2, 0x3f, 0xbb, 0xfb, 0x47, 0x07,
+	0xbc, 0x8f, 0x67, 0x47, 0x93, 0x28, 0x14, 0xae, 0xbc, 0xad, 0x02, 0x97, 0xdd, 0xec, 0xc0, 0xb9,
+	0x11, 0xe5, 0xf8, 0x92, 0xaa, 0x72, 0xab, 0x05, 0x46, 0xfc, 0x73, 0x2a, 0xcd, 0xbf, 0x05, 0x0d,
+	0xe5, 0x9a, 0x26, 0xe2, 0x2a, 0xb8, 0xc7, 0x78, 0xaa, 0x38, 0xa8, 0xf7, 0x56, 0x55, 0xe4, 0xad,
+	0xb3, 0x81, 0x5c, 0xf4, 0x39, 0x9c, 0xfb, 0x08, 0x19, 0xa3, 0x23, 0x24, 0x5b, 0x50, 0x65, 0x18,
+	0x0f, 0x31, 0xd3, 0xbe, 0x6b, 0x89, 0x6c, 0x40, 0xe5, 0x84, 0x4e, 0x66, 0x28, 0x7d, 0xf7, 0x02,
+	0x25, 0xbc, 0x3d, 0xc8, 0x0b, 0x85, 0xe4, 0x2e, 0x14, 0x92, 0xff, 0xab, 0x03, 0xb5, 0x47, 0xf1,
+	0x09, 0x4e, 0x92, 0x14, 0x05, 0x8f, 0x53, 0x75, 0x05, 0x9d, 0x5e, 0x46, 0x24, 0x04, 0xdc, 0x2c,
+	0x49, 0xa6, 0xfa, 0x60, 0xf9, 0x4d, 0xae, 0x6b, 0xaf, 0xca, 0xd2, 0xab, 0x8e, 0xf2, 0xca, 0xd8,
+	0x12, 0x89, 0xcd, 0x1e, 0xc5, 0x3c, 0xd3, 0xee, 0x15, 0x7c, 0x72, 0xe7, 0x7c, 0x9a, 0xbb, 0x7d,
+	0x65, 0xf1, 0xf6, 0x1b, 0x50, 0x61, 0x9c, 0x4e, 0x53, 0x4d, 0xb2, 0x12, 0xba, 0x4f, 0xc1, 0xb3,
+	0xe6, 0x4d, 0x3a, 0x28, 0xa6, 0xc4, 0x27, 0xb9, 0x56, 0xa4, 0xa9, 0xde, 0x6b, 0xab, 0x9b, 0x7d,
+	0x96, 0xd1, 0x34, 0xc5, 0xa1, 0x20, 0x5c, 0x2d, 0xff, 0xaf, 0xf4, 0x5f, 0xc7, 0x7f, 0x06, 0x90,
+	0x2f, 0x14, 0x6d, 0xe9, 0xd4, 0xda, 0x85, 0xea, 0x18, 0xa9, 0xb8, 0xb6, 0x32, 0xb6, 0xae, 0x8c,
+	0x05, 0x2a, 0x2d, 0x9e, 0xc8, 0xa5, 0x40, 0xab, 0xf8, 0xdf, 0x3a, 0xd0, 0x9c, 0x5b, 0x11, 0x8c,
+	0x32, 0x64, 0xb2, 0x83, 0x68, 0x46, 0xb5, 0x28, 0x3a, 0x58, 0x86, 0x61, 0x94, 0x2e, 0x8c, 0x92,
+	0x86, 0x05, 0xc5, 0x7d, 0x2e, 0x01, 0xa4, 0x32, 0x4d, 0x0a, 0xa3, 0xc4, 0x4b, 0x6d, 0x95, 0xfc,
+	0x03, 0x56, 0x6d, 0x4f, 0x98, 0x60, 0x3c, 0xe2, 0x63, 0xdd, 0xa7, 0x5a, 0x06, 0x3e, 0x90, 0xa8,
+	0x20, 0x3f, 0x9e, 0x4d, 0x8f, 0x30, 0x93, 0x0c, 0x37, 0x03, 0x2d, 0xf9, 0x2f, 0xa0, 0xf6, 0x41,
+	0x34, 0xc1, 0xa7, 0xf1, 0xf3, 0x44, 0x84, 0xb8, 0x50, 0x6e, 0xf2, 0x5b, 0xb6, 0xb7, 0x24, 0xe6,
+	0xe2, 0x8a, 0xfc, 0x34, 0x35, 0x79, 0x57, 0xd7, 0xd8, 0x27, 0xa7, 0xa9, 0xcc, 0x55, 0x7d, 0xb4,
+	0xb8, 0x5e, 0x39, 0xd0, 0x92, 0xc0, 0xd9, 0x98, 0xf6, 0x6e, 0xdf, 0xd1, 0x39, 0xa7, 0x25, 0xff,
+	0x10, 0x9a, 0x87, 0xe9, 0x24, 0xa1, 0x43, 0xd3, 0x74, 0xfe, 0x06, 0x6e, 0x14, 0x3f, 0x4f, 0xe4,
+	0xb9, 0x76, 0xca, 0x98, 0x5b, 0x3d, 0x59, 0x09, 0xe4, 0x2a, 0xd9, 0x82, 0x4a, 0x38, 0x9e, 0xc5,
+	0xc7, 0x8a, 0xa6, 0x27, 0x2b, 0x81, 0x12, 0xef, 0x57, 0xc1, 0x1d, 0x52, 0x4e, 0xfd, 0x6d, 0x68,
+	0x19, 0xb3, 0xba, 0xe8, 0x5a, 0x50, 0x8a, 0x86, 0xda, 0x9b, 0x52, 0x34, 0xf4, 0xaf, 0xc0, 0xea,
+	0xc3, 0xe4, 0x65, 0x5c, 0x3c, 0x7a, 0x51, 0xe5, 0x73, 0x68, 0xe7, 0x2a, 0xda, 0xcc, 0x1f, 0x73,
+	0x3d, 0x02, 0xed, 0xbe, 0xa9, 0x39, 0xd3, 0xf1, 0xfe, 0x0e, 0x6b, 0x05, 0x4c, 0x1f, 0xb7, 0x94,
+	0x81, 0xfe, 0x26, 0xac, 0x1f, 0x44, 0x8c, 0xf7, 0x55, 0xde, 0xd8, 0x7e, 0xf9, 0x4d, 0x09, 0xea,
+	0x1a, 0x93, 0xe1, 0x7b, 0x5b, 0xc7, 0x54, 0x7e, 0x96, 0x8c, 0x9f, 0x22, 0xd4, 0xc7, 0x51, 0x3c,
+	0x94, 0x11, 0xf3, 0x02, 0xf9, 0x2d, 0xb0, 0x14, 0x6d, 0x75, 0xca, 0x6f, 0x1d, 0xfe, 0x18, 0x43,
+	0x8e, 0xc3, 0x01, 0xe5, 0x32, 0x79, 0xca, 0x41, 0xdd, 0x62, 0xfb, 0x7c, 0x69, 0x00, 0x56, 0x97,
+	0x07, 0xe0, 0xef, 0x78, 0x77, 0x6c, 0x40, 0x45, 0xb4, 0x16, 0xf5, 0xe4, 0xf0, 0x02, 0x25, 0x88,
+	0x7e, 0xfb, 0x62, 0x86, 0x33, 0x1c, 0x0c, 0x31, 0xe5, 0xe3, 0x8e, 0x27, 0xf7, 0x82, 0x84, 0x1e,
+	0x62, 0xaa, 0x72, 0xed, 0xcb, 0x24, 0x8a, 0x71, 0xd8, 0x01, 0xd9, 0xfe, 0xb5, 0xe4, 0x3f, 0x82,
+	0x8d, 0x79, 0xea, 0x34, 0xc9, 0x7b, 0x50, 0xd3, 0x65, 0x68, 0x7a, 0xb2, 0x9e, 0xc6, 0x05, 0x42,
+	0x03, 0xab, 0xe2, 0x3f, 0x86, 0xb5, 0x87, 0x11, 0xd3, 0x5e, 0xbf, 0xcb, 0x1b, 0x71, 0x0b, 0xaa,
+	0x19, 0x52, 0x96, 0xc4, 0x9a, 0x73, 0x2d, 0xf9, 0x1b, 0x40, 0x8a, 0x86, 0xf4, 0x1c, 0xbd, 0x0b,
+	0xab, 0xfb, 0x71, 0x9c, 0xcc, 0xe2, 0x10, 0x8d, 0x71, 0x02, 0x2e, 0xc7, 0x57, 0xdc, 0xd4, 0xa2,
+	0xf8, 0x3e, 0xab, 0x05, 0x8b, 0xb4, 0xca, 0xb7, 0xe6, 0x63, 0xb9, 0xcf, 0x29, 0xb7, 0x89, 0xf2,
+	0x4b, 0x09, 0x9a, 0x1a, 0x28, 0xbc, 0xad, 0x38, 0xcd, 0x74, 0x50, 0x1d, 0x19, 0x54, 0x4f, 0x23,
+	0xfb, 0x5c, 0xf4, 0x2c, 0xf5, 0x9e, 0x65, 0xf2, 0xac, 0x66, 0x60, 0x44, 0xe1, 0xb3, 0x1e, 0xac,
+	0x4c, 0xe6, 0x4e, 0x33, 0xb0, 0x32, 0xd9, 0x86, 0x3a, 0x9b, 0x1d, 0xb1, 0x30, 0x8b, 0x8e, 0x30,
+	0x63, 0xba, 0x0f, 0x15, 0x21, 0xb2, 0x0b, 0x6b, 0x47, 0x59, 0x42, 0x87, 0x21, 0x65, 0x7c, 0x70,
+	0x44, 0xc3, 0xe3, 0x49, 0x32, 0xd2, 0xfd, 0xa8, 0x6d, 0x17, 0xee, 0x2b, 0x9c, 0xec, 0x01, 0xc9,
+	0x95, 0x43, 0x9a, 0xd2, 0x30, 0xe2, 0x6a, 0xd4, 0x36, 0x83, 0xdc, 0xcc, 0x03, 0xbd, 0x20, 0xbb,
+	0x90, 0x78, 0x3f, 0x32, 0x99, 0x59, 0x6e, 0xa0, 0x25, 0xd1, 0x65, 0x9f, 0xd3, 0x68, 0x82, 0xc3,
+	0x81, 0x5e, 0xae, 0xc9, 0xe5, 0x86, 0x02, 0x0f, 0x94, 0xd2, 0x2e, 0xac, 0xe9, 0x39, 0xc7, 0x06,
+	0x19, 0x86, 0x18, 0x9d, 0xe0, 0x50, 0x66, 0x99, 0x1b, 0xb4, 0xcd, 0x42, 0xa0, 0x71, 0x61, 0xd1,
+	0x2a, 0x33, 0x8c, 0xb9, 0x4c, 0x39, 0x37, 0x68, 0x18, 0xb0, 0x8f, 0x31, 0x17, 0x81, 0x7e, 0x8c,
+	0xbc, 0x8f, 0x9c, 0x47, 0xf1, 0xc8, 0x46, 0x82, 0x43, 0xcd, 0x40, 0xc2, 0x4c, 0xb1, 0x6e, 0xcc,
+	0xfb, 0xa8, 0x51, 0x28, 0x1c, 0x46, 0x2e, 0x80, 0x37, 0x49, 0x46, 0x83, 0x09, 0x9e, 0xe0, 0x44,
+	0xc7, 0xbd, 0x36, 0x49, 0x46, 0x07, 0x42, 0x26, 0xd7, 0x60, 0x75, 0x4a, 0x5f, 0x0d, 0x66, 0xb2,
+	0xeb, 0x0d, 0x58, 0xf4, 0x1a, 0x75, 0x07, 0x6e, 0x4e, 0xe9, 0x2b, 0xd5, 0x0b, 0xfb, 0xd1, 0x6b,
+	0xf4, 0x1f, 0xc0, 0xe6, 0x61, 0x3a, 0xa4, 0x1c, 0x17, 0xae, 0x43, 0xfe, 0x25, 0xaa, 0x40, 0x41,
+	0xf3, 0xdd, 0xcd, 0x2a, 0xda, 0xf5, 0xde, 0x77, 0x65, 0x70, 0x1f, 0x8c, 0x29, 0x27, 0x3d, 0xa8,
+	0x48, 0xd6, 0x08, 0x51, 0xba, 0xc5, 0xff, 0xa6, 0xee, 0xfa, 0x1c, 0xa6, 0xf3, 0x71, 0x85, 0xdc,
+	0x86, 0xaa, 0x7a, 0x3a, 0x92, 0x5c, 0x21, 0x7f, 0x75, 0x76, 0x37, 0xe6, 0x41, 0xbb, 0xed, 0x3a,
+	0xb8, 0x1f, 0x26, 0x51, 0x4c, 0x5a, 0xf3, 0x2f, 0x8b, 0xee, 0x82, 0xec, 0xaf, 0xec, 0x38, 0x37,
+	0x1d, 0x71, 0x31, 0xf9, 0xfa, 0x34, 0x17, 0x2b, 0x3e, 0x4d, 0xbb, 0xeb, 0x73, 0x98, 0x3d, 0xe1,
+	0xdf, 0xe0, 0x8a, 0x77, 0x04, 0xc9, 0xdf, 0xe2, 0x76, 0x07, 0x29, 0x42, 0x76, 0xc3, 0x7f, 0xa0,
+	0xaa, 0x98, 0x35, 0x9e, 0xcc, 0x8d, 0xb2, 0xee, 0xc6, 0x3c, 0x68, 0xb6, 0xed, 0x38, 0xe4, 0x3d,
+	0xa8, 0x99, 0xc9, 0x42, 0x36, 0x95, 0xd6, 0xc2, 0x30, 0xea, 0x6e, 0x2d, 0xc2, 0x66, 0xfb, 0x4d,
+	0x87, 0xbc, 0x0f, 0x9e, 0x1d, 0x15, 0x64, 0xcb, 0x44, 0x69, 0x7e, 0x9e, 0x74, 0xcf, 0x2f, 0xe1,
+	0xc6, 0x42, 0xef, 0xab, 0x32, 0x54, 0xf6, 0x87, 0xd3, 0x28, 0x26, 0x8f, 0xa1, 0x51, 0x6c, 0x89,
+	0xe4, 0x2f, 0x9a, 0xfc, 0xe5, 0x09, 0xd3, 0xed, 0x9e, 0xb5, 0x64, 0xa9, 0xd8, 0x07, 0xc8, 0x7b,
+	0x19, 0xd1, 0x67, 0x2f, 0xb5, 0xc9, 0x6e, 0x67, 0x79, 0xc1, 0x9a, 0xf8, 0x3f, 0xd4, 0x4c, 0xf7,
+	0x32, 0xa4, 0x2c, 0x34, 0xc2, 0xee, 0xd6, 0x22, 0x6c, 0x37, 0xf7, 0xa0, 0x22, 0xbb, 0x9a, 0x89,
+	0x77, 0xb1, 0xe7, 0x75, 0xd7, 0xe7, 0x30, 0xbb, 0xe7, 0x2e, 0xd4, 0x0b, 0x65, 0x49, 0xf4, 0xdd,
+	0x96, 0x2b, 0xb5, 0xbb, 0x50, 0x08, 0xfe, 0x0a, 0xb9, 0x07, 0xad, 0xf9, 0x2a, 0x22, 0x17, 0x4c,
+	0xb0, 0xcf, 0xa8, 0xad, 0x65, 0x03, 0xf7, 0x1b, 0x3f, 0xbc, 0xb9, 0xec, 0xfc, 0xf4, 0xe6, 0xb2,
+	0xf3, 0xf3, 0x9b, 0xcb, 0xce, 0x51, 0x55, 0x4e, 0xbe, 0x5b, 0xbf, 0x0d, 0x00, 0x73, 0x3d, 0x22,
+	0x4c, 0x8d, 0x10, 0x00, 0x00,
 }
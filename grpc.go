@@ -1,29 +1,70 @@
 package main
 
 import (
+	"log/slog"
 	"net"
+	"time"
 
 	"github.com/danielcopaciu/chat/generated/chat"
 
-	"github.com/cloudflare/cfssl/log"
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
-func startGRPCServer(address string, creds credentials.TransportCredentials, server chat.ChatServer) (func(), error) {
+const (
+	chatProto   = "chat.proto"
+	chatService = "chat.Chat"
+
+	// stopTimeout bounds how long the server waits for calls to finish
+	// before closing their connections.
+	stopTimeout = 5 * time.Second
+)
+
+func init() {
+	// The chat service is generated with gogo protobuf, which keeps its
+	// descriptors apart from the registry server reflection reads.
+	proto.RegisterFile(chatProto, gogoproto.FileDescriptor(chatProto))
+}
+
+// startGRPCServer serves the chat service on address, together with server
+// reflection and the standard health service. Health is reported SERVING
+// until the returned function starts draining the server.
+func startGRPCServer(address string, creds credentials.TransportCredentials, server chat.ChatServer, healthServer *health.Server, opts ...grpc.ServerOption) (func(), error) {
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		return nil, err
 	}
 
-	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{grpc.Creds(creds)}, opts...)...)
 	chat.RegisterChatServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(chatService, healthpb.HealthCheckResponse_SERVING)
 
-	log.Infof("Starting GRPC server on: %s", address)
+	slog.Info("Starting gRPC server", "address", address)
 	go grpcServer.Serve(lis)
 
 	return func() {
-		log.Info("Stopping GRPC server")
-		grpcServer.GracefulStop()
+		slog.Info("Stopping gRPC server")
+		healthServer.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(stopTimeout):
+			slog.Warn("Calls still running, closing their connections", "timeout", stopTimeout.String())
+			grpcServer.Stop()
+		}
 	}, nil
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/danielcopaciu/chat/identity"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// passphraseEnv holds the passphrase of an encrypted identity when it
+// cannot be asked for on a terminal.
+const passphraseEnv = "PASSPHRASE"
+
+func identityPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return identity.DefaultPath()
+}
+
+// loadIdentity loads the identity at path, creating an unencrypted one the
+// first time the client runs.
+func loadIdentity(path string) (*identity.Identity, error) {
+	path, err := identityPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := identity.Load(path, passphraseFor(path))
+	if err == nil || !os.IsNotExist(errors.Cause(err)) {
+		return id, err
+	}
+
+	slog.Info("Creating a new identity", "path", path)
+	if id, err = identity.Generate(); err != nil {
+		return nil, err
+	}
+	if err := id.Save(path, nil); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+func runKeygen(path string, force, encrypt bool) error {
+	path, err := identityPath(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return errors.Errorf("%s already exists, use --force to replace it", path)
+	}
+
+	var passphrase []byte
+	if encrypt {
+		if passphrase, err = readPassphrase("Passphrase: "); err != nil {
+			return err
+		}
+		if os.Getenv(passphraseEnv) == "" {
+			confirmation, err := readPassphrase("Repeat passphrase: ")
+			if err != nil {
+				return err
+			}
+			if string(confirmation) != string(passphrase) {
+				return errors.New("passphrases do not match")
+			}
+		}
+	}
+
+	id, err := identity.Generate()
+	if err != nil {
+		return err
+	}
+	if err := id.Save(path, passphrase); err != nil {
+		return err
+	}
+
+	fingerprint, err := id.Fingerprint()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Identity saved to %s\nFingerprint: %s\n", path, fingerprint)
+	return nil
+}
+
+func runFingerprint(path string) error {
+	path, err := identityPath(path)
+	if err != nil {
+		return err
+	}
+
+	id, err := identity.Load(path, passphraseFor(path))
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := id.Fingerprint()
+	if err != nil {
+		return err
+	}
+	fmt.Println(fingerprint)
+	return nil
+}
+
+func passphraseFor(path string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return readPassphrase(fmt.Sprintf("Passphrase for %s: ", path))
+	}
+}
+
+// readPassphrase takes the passphrase from the environment, or asks for it
+// on the terminal without echoing it.
+func readPassphrase(prompt string) ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, errors.Errorf("a passphrase is required, set %s to provide it", passphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	passphrase, err := terminal.ReadPassword(fd)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	return passphrase, nil
+}
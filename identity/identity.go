@@ -0,0 +1,221 @@
+// Package identity stores the long-lived keys a user is known by, so that
+// other users can recognise them from one session to the next.
+package identity
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// FileName is the name of the identity file in the config directory.
+	FileName = "identity.pem"
+
+	keyBlock       = "PRIVATE KEY"
+	encryptedBlock = "ENCRYPTED CHAT IDENTITY"
+	saltSize       = 16
+)
+
+// ErrPassphrase is returned when an encrypted identity cannot be opened
+// with the given passphrase.
+var ErrPassphrase = errors.New("wrong passphrase")
+
+// Identity holds the private keys of a user: the RSA key messages are
+// encrypted for and the Ed25519 key messages are signed with.
+type Identity struct {
+	EncryptionKey *rsa.PrivateKey
+	SigningKey    ed25519.PrivateKey
+}
+
+// Generate creates a new identity.
+func Generate() (*Identity, error) {
+	encryptionKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate key")
+	}
+
+	signingKey, err := secure.NewSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{EncryptionKey: encryptionKey, SigningKey: signingKey}, nil
+}
+
+// ConfigDir returns the directory the client keeps its files in.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chat"), nil
+}
+
+// DefaultPath returns the path of the identity file in the user's config
+// directory.
+func DefaultPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Fingerprint summarises the public keys of the identity.
+func (id *Identity) Fingerprint() (string, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(&id.EncryptionKey.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return secure.Fingerprint(publicKey, id.SigningKey.Public().(ed25519.PublicKey)), nil
+}
+
+// Save writes the identity to path, readable only by the user. It is
+// encrypted with the passphrase unless that is empty.
+func (id *Identity) Save(path string, passphrase []byte) error {
+	data, err := id.marshal()
+	if err != nil {
+		return err
+	}
+
+	if len(passphrase) > 0 {
+		if data, err = encrypt(data, passphrase); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.WithMessage(err, "failed to create config directory")
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// Load reads the identity at path. The passphrase is only asked for when
+// the identity is encrypted.
+func Load(path string, passphrase func() ([]byte, error)) (*Identity, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(data); block != nil && block.Type == encryptedBlock {
+		secret, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if data, err = decrypt(block, secret); err != nil {
+			return nil, err
+		}
+	}
+	return unmarshal(data)
+}
+
+func (id *Identity) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range []interface{}{id.EncryptionKey, id.SigningKey} {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := pem.Encode(&buf, &pem.Block{Type: keyBlock, Bytes: der}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshal(data []byte) (*Identity, error) {
+	id := &Identity{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != keyBlock {
+			continue
+		}
+
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid identity")
+		}
+
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			id.EncryptionKey = key
+		case ed25519.PrivateKey:
+			id.SigningKey = key
+		}
+	}
+
+	if id.EncryptionKey == nil || id.SigningKey == nil {
+		return nil, errors.New("identity is missing keys")
+	}
+	return id, nil
+}
+
+// encrypt seals the identity with a key derived from the passphrase with
+// scrypt.
+func encrypt(data, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := passphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := secure.Seal(aead, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    encryptedBlock,
+		Headers: map[string]string{"KDF": "scrypt", "Salt": hex.EncodeToString(salt)},
+		Bytes:   sealed,
+	}), nil
+}
+
+func decrypt(block *pem.Block, passphrase []byte) ([]byte, error) {
+	salt, err := hex.DecodeString(block.Headers["Salt"])
+	if err != nil || block.Headers["KDF"] != "scrypt" {
+		return nil, errors.New("invalid identity")
+	}
+
+	aead, err := passphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := secure.Open(aead, block.Bytes, nil)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	return data, nil
+}
+
+func passphraseAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, secure.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return secure.NewAEAD(key)
+}
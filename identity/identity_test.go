@@ -0,0 +1,115 @@
+package identity
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func passphrase(secret string) func() ([]byte, error) {
+	return func() ([]byte, error) { return []byte(secret), nil }
+}
+
+func TestSaveLoad(t *testing.T) {
+	id, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := id.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	plain, encrypted := filepath.Join(dir, "plain.pem"), filepath.Join(dir, "chat", FileName)
+	if err := id.Save(plain, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := id.Save(encrypted, []byte("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+
+	asked := false
+	loaded, err := Load(plain, func() ([]byte, error) { asked = true; return nil, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asked {
+		t.Error("asked for the passphrase of a plain identity")
+	}
+	if got, _ := loaded.Fingerprint(); got != want {
+		t.Errorf("plain identity has fingerprint %s, want %s", got, want)
+	}
+
+	loaded, err = Load(encrypted, passphrase("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := loaded.Fingerprint(); got != want {
+		t.Errorf("encrypted identity has fingerprint %s, want %s", got, want)
+	}
+	if _, err := Load(encrypted, passphrase("wrong horse")); err != ErrPassphrase {
+		t.Errorf("got %v, want %v", err, ErrPassphrase)
+	}
+
+	data, err := ioutil.ReadFile(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("PRIVATE KEY")) {
+		t.Error("the encrypted identity contains a plain key")
+	}
+}
+
+func TestLoadTampered(t *testing.T) {
+	id, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := id.Save(path, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	block.Bytes[len(block.Bytes)-1] ^= 1
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, passphrase("secret")); err == nil {
+		t.Error("loaded a tampered identity")
+	}
+}
+
+func TestLoadMissingKey(t *testing.T) {
+	id, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := id.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(data)
+	if _, err := unmarshal(pem.EncodeToMemory(block)); err == nil {
+		t.Error("loaded an identity without its signing key")
+	}
+
+	failed := errors.New("no terminal")
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := id.Save(path, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, func() ([]byte, error) { return nil, failed }); err != failed {
+		t.Errorf("got %v, want %v", err, failed)
+	}
+}
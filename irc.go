@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+
+	"github.com/danielcopaciu/chat/server"
+)
+
+func startIRCServer(address string, chatServer *server.Server) (func(), error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway := server.NewIRCGateway(chatServer)
+
+	slog.Info("Starting IRC gateway", "address", address)
+	go gateway.Serve(lis)
+
+	return func() {
+		slog.Info("Stopping IRC gateway")
+		lis.Close()
+		gateway.Close()
+	}, nil
+}
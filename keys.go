@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/danielcopaciu/chat/server"
+	"github.com/pkg/errors"
+)
+
+// loadServerKeys loads the server keys from keyFile, or from keyDir when it
+// is empty, and parses the rotation settings.
+func loadServerKeys(keyFile, keyDir, rotation, overlap string) (*server.KeyRing, time.Duration, error) {
+	var interval time.Duration
+	if rotation != "" {
+		var err error
+		if interval, err = time.ParseDuration(rotation); err != nil {
+			return nil, 0, errors.WithMessage(err, "invalid key rotation interval")
+		}
+	}
+
+	var keys *server.KeyRing
+	if keyFile != "" {
+		if interval > 0 {
+			return nil, 0, errors.New("a server key file cannot be rotated, use a key directory")
+		}
+
+		var err error
+		if keys, err = server.LoadKeyFile(keyFile); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		window, err := time.ParseDuration(overlap)
+		if err != nil {
+			return nil, 0, errors.WithMessage(err, "invalid key overlap")
+		}
+		if keys, err = server.LoadKeyDir(keyDir, window); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	fingerprint, err := secure.KeyFingerprint(&keys.Current().PublicKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	slog.Info("Loaded server key", "fingerprint", fingerprint)
+	return keys, interval, nil
+}
@@ -6,16 +6,26 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/danielcopaciu/chat/client"
-	"golang.org/x/crypto/acme/autocert"
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/identity"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/danielcopaciu/chat/telemetry"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
 
 	"github.com/danielcopaciu/chat/server"
+	"github.com/danielcopaciu/chat/web"
 
 	cli "github.com/jawher/mow.cli"
 )
@@ -35,50 +45,382 @@ var (
 func main() {
 	app := cli.App(appMeta.name, appMeta.description)
 
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	app.Command("server", "Run server chat", func(cmd *cli.Cmd) {
-		address := app.String(cli.StringOpt{
+		conf := cfg.section("server")
+		conf.strict()
+		cfg.configOption(cmd)
+		var overrides serverOverrides
+		address := cmd.String(cli.StringOpt{
 			Name:   "address",
-			Value:  fmt.Sprintf("%s:%s", defaultAddress, defaultPort),
+			Value:  conf.String("address", fmt.Sprintf("%s:%s", defaultAddress, defaultPort)),
 			Desc:   "GRPC address",
 			EnvVar: "ADDRESS",
 		})
-		insecure := app.Bool(cli.BoolOpt{
+		insecure := cmd.Bool(cli.BoolOpt{
 			Name:   "insecure",
-			Value:  true,
+			Value:  conf.Bool("insecure", true),
 			Desc:   "Flag to run server without tls",
 			EnvVar: "INSECURE",
 		})
-		certDir := app.String(cli.StringOpt{
+		tlsCert := cmd.String(cli.StringOpt{
+			Name:   "tls-cert",
+			Value:  conf.String("tls-cert", ""),
+			Desc:   "TLS certificate to serve instead of requesting one with acme, reloaded when it changes (effective if insecure is false)",
+			EnvVar: "TLS_CERT",
+		})
+		tlsKey := cmd.String(cli.StringOpt{
+			Name:   "tls-key",
+			Value:  conf.String("tls-key", ""),
+			Desc:   "Key of the TLS certificate",
+			EnvVar: "TLS_KEY",
+		})
+		certDir := cmd.String(cli.StringOpt{
 			Name:   "cert-dir",
-			Value:  "tls",
+			Value:  conf.String("cert-dir", "tls"),
 			Desc:   "Directory to cache acme certs (effective if insecure is false)",
 			EnvVar: "CERT_DIR",
 		})
-		domain := app.String(cli.StringOpt{
+		domains := cmd.Strings(cli.StringsOpt{
 			Name:   "domain",
-			Value:  "chat.dragffy.ro",
-			Desc:   "Domain name to register cert with (effective if insecure is false)",
+			Value:  conf.Strings("domain", []string{"chat.dragffy.ro"}),
+			Desc:   "Domain names to register certs with, repeat for several (effective if insecure is false)",
 			EnvVar: "DOMAIN",
 		})
+		acmeDirectory := cmd.String(cli.StringOpt{
+			Name:   "acme-directory",
+			Value:  conf.String("acme-directory", ""),
+			Desc:   "Directory URL of the acme CA (defaults to Let's Encrypt)",
+			EnvVar: "ACME_DIRECTORY",
+		})
+		acmeEmail := cmd.String(cli.StringOpt{
+			Name:   "acme-email",
+			Value:  conf.String("acme-email", ""),
+			Desc:   "Contact email of the acme account",
+			EnvVar: "ACME_EMAIL",
+		})
+		acmeCA := cmd.String(cli.StringOpt{
+			Name:   "acme-ca-file",
+			Value:  conf.String("acme-ca-file", ""),
+			Desc:   "CA file to verify the acme directory with instead of the system roots",
+			EnvVar: "ACME_CA_FILE",
+		})
+		acmeChallenge := cmd.String(cli.StringOpt{
+			Name:   "acme-challenge",
+			Value:  conf.String("acme-challenge", challengeHTTP),
+			Desc:   "Challenge to prove control of the domains with: http-01 (served on port 80) or tls-alpn-01 (answered by the server itself, which must be reachable on port 443)",
+			EnvVar: "ACME_CHALLENGE",
+		})
+		clientCA := cmd.String(cli.StringOpt{
+			Name:   "client-ca",
+			Value:  conf.String("client-ca", ""),
+			Desc:   "CA file to require client certificates signed by, naming users by their common name (effective if insecure is false)",
+			EnvVar: "CLIENT_CA",
+		})
+		keyFile := cmd.String(cli.StringOpt{
+			Name:   "key-file",
+			Value:  conf.String("key-file", ""),
+			Desc:   "PEM file of the server key, generated on first run (overrides key-dir)",
+			EnvVar: "KEY_FILE",
+		})
+		keyDir := cmd.String(cli.StringOpt{
+			Name:   "key-dir",
+			Value:  conf.String("key-dir", "keys"),
+			Desc:   "Directory to keep the server keys in, generated on first run",
+			EnvVar: "KEY_DIR",
+		})
+		keyRotation := cmd.String(cli.StringOpt{
+			Name:   "key-rotation",
+			Value:  conf.String("key-rotation", ""),
+			Desc:   "Interval to rotate the server key at, e.g. 720h (disabled if empty)",
+			EnvVar: "KEY_ROTATION",
+		})
+		keyOverlap := cmd.String(cli.StringOpt{
+			Name:   "key-overlap",
+			Value:  conf.String("key-overlap", "24h"),
+			Desc:   "How long a rotated server key is still accepted",
+			EnvVar: "KEY_OVERLAP",
+		})
+		cipherSuites := cmd.Strings(cli.StringsOpt{
+			Name:   "cipher-suites",
+			Value:  conf.Strings("cipher-suites", secure.SuiteNames()),
+			Desc:   "Cipher suites clients may agree on the session key with, repeat for several",
+			EnvVar: "CIPHER_SUITES",
+		})
+		blobDir := cmd.String(cli.StringOpt{
+			Name:   "blob-dir",
+			Value:  conf.String("blob-dir", "blobs"),
+			Desc:   "Directory to store uploaded files in",
+			EnvVar: "BLOB_DIR",
+		})
+		maxUploadSize := cmd.Int(cli.IntOpt{
+			Name:      "max-upload-size",
+			Value:     conf.Int("max-upload-size", defaultServerSettings.maxUploadSize),
+			Desc:      "Maximum size in bytes of an uploaded file, reloaded from the config file on SIGHUP",
+			EnvVar:    "MAX_UPLOAD_SIZE",
+			SetByUser: &overrides.maxUploadSize,
+		})
+		uploadQuota := cmd.Int(cli.IntOpt{
+			Name:   "upload-quota",
+			Value:  conf.Int("upload-quota", 100<<20),
+			Desc:   "Bytes of uploaded files each user may store (unlimited if 0)",
+			EnvVar: "UPLOAD_QUOTA",
+		})
+		storageQuota := cmd.Int(cli.IntOpt{
+			Name:   "storage-quota",
+			Value:  conf.Int("storage-quota", 1<<30),
+			Desc:   "Bytes of uploaded files the server stores in all (unlimited if 0)",
+			EnvVar: "STORAGE_QUOTA",
+		})
+		motd := cmd.String(cli.StringOpt{
+			Name:      "motd",
+			Value:     conf.String("motd", defaultServerSettings.motd),
+			Desc:      "Message of the day shown to users as they log in, reloaded from the config file on SIGHUP",
+			EnvVar:    "MOTD",
+			SetByUser: &overrides.motd,
+		})
+		rateLimit := cmd.Int(cli.IntOpt{
+			Name:      "rate-limit",
+			Value:     conf.Int("rate-limit", defaultServerSettings.rateLimit),
+			Desc:      "Messages a minute each user may send, dropping the rest (unlimited if 0), reloaded from the config file on SIGHUP",
+			EnvVar:    "RATE_LIMIT",
+			SetByUser: &overrides.rateLimit,
+		})
+		rateBurst := cmd.Int(cli.IntOpt{
+			Name:      "rate-burst",
+			Value:     conf.Int("rate-burst", defaultServerSettings.rateBurst),
+			Desc:      "Messages each user may send at once before the rate limit applies, reloaded from the config file on SIGHUP",
+			EnvVar:    "RATE_BURST",
+			SetByUser: &overrides.rateBurst,
+		})
+		webAddress := cmd.String(cli.StringOpt{
+			Name:   "web",
+			Value:  conf.String("web", ""),
+			Desc:   "HTTP address to serve the web chat UI on (disabled if empty)",
+			EnvVar: "WEB_ADDRESS",
+		})
+		webCAFile := cmd.String(cli.StringOpt{
+			Name:   "web-ca-file",
+			Value:  conf.String("web-ca-file", ""),
+			Desc:   "CA file the web UI trusts the server certificate with instead of the system roots",
+			EnvVar: "WEB_CA_FILE",
+		})
+		webServerName := cmd.String(cli.StringOpt{
+			Name:   "web-server-name",
+			Value:  conf.String("web-server-name", ""),
+			Desc:   "Name the web UI verifies the server certificate against, if not the host of the address (or the first domain with acme)",
+			EnvVar: "WEB_SERVER_NAME",
+		})
+		ircAddress := cmd.String(cli.StringOpt{
+			Name:   "irc",
+			Value:  conf.String("irc", ""),
+			Desc:   "Address to accept IRC clients on (disabled if empty)",
+			EnvVar: "IRC_ADDRESS",
+		})
+		streamAddress := cmd.String(cli.StringOpt{
+			Name:   "stream",
+			Value:  conf.String("stream", ""),
+			Desc:   "HTTP address to stream the conversation as server-sent events on (disabled if empty)",
+			EnvVar: "STREAM_ADDRESS",
+		})
+		streamToken := cmd.String(cli.StringOpt{
+			Name:   "stream-token",
+			Value:  conf.String("stream-token", ""),
+			Desc:   "Read token required by the event stream",
+			EnvVar: "STREAM_TOKEN",
+		})
+		metricsAddress := cmd.String(cli.StringOpt{
+			Name:   "metrics-address",
+			Value:  conf.String("metrics-address", ""),
+			Desc:   "HTTP address to serve Prometheus metrics on /metrics (disabled if empty)",
+			EnvVar: "METRICS_ADDRESS",
+		})
+		logFormat := cmd.String(cli.StringOpt{
+			Name:   "log-format",
+			Value:  conf.String("log-format", telemetry.FormatJSON),
+			Desc:   "Format of the logs (json or text)",
+			EnvVar: "LOG_FORMAT",
+		})
+		logLevel := cmd.String(cli.StringOpt{
+			Name:      "log-level",
+			Value:     conf.String("log-level", defaultServerSettings.logLevel),
+			Desc:      "Lowest level to log (debug, info, warn or error), reloaded from the config file on SIGHUP",
+			EnvVar:    "LOG_LEVEL",
+			SetByUser: &overrides.logLevel,
+		})
+		traceOutput := cmd.String(cli.StringOpt{
+			Name:   "trace-output",
+			Value:  conf.String("trace-output", ""),
+			Desc:   "Where to export OpenTelemetry spans: stdout, or a file to append OTLP JSON to (disabled if empty)",
+			EnvVar: "TRACE_OUTPUT",
+		})
+		shutdownTimeout := cmd.String(cli.StringOpt{
+			Name:   "shutdown-timeout",
+			Value:  conf.String("shutdown-timeout", "10s"),
+			Desc:   "How long to wait on shutdown for queued messages to be delivered",
+			EnvVar: "SHUTDOWN_TIMEOUT",
+		})
+		retryAfter := cmd.String(cli.StringOpt{
+			Name:   "retry-after",
+			Value:  conf.String("retry-after", "5s"),
+			Desc:   "Delay clients are asked to reconnect after when the server shuts down",
+			EnvVar: "RETRY_AFTER",
+		})
+		adminAddress := cmd.String(cli.StringOpt{
+			Name:   "admin-address",
+			Value:  conf.String("admin-address", ""),
+			Desc:   "GRPC address to serve the admin service on (disabled if empty)",
+			EnvVar: "ADMIN_ADDRESS",
+		})
+		adminToken := cmd.String(cli.StringOpt{
+			Name:   "admin-token",
+			Value:  conf.String("admin-token", ""),
+			Desc:   "Token required by the admin service",
+			EnvVar: "ADMIN_TOKEN",
+		})
 
 		cmd.Action = func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			level := new(slog.LevelVar)
+			if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+				log.Fatalf("invalid log level %q", *logLevel)
+			}
+
+			telemetryStop, err := setupTelemetry(*logFormat, level, *traceOutput)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer telemetryStop()
+
+			if *clientCA != "" && *insecure {
+				log.Fatal("client certificates require TLS, run the server with --insecure=false")
+			}
+			// Neither the web bridge nor the IRC gateway can bind the users
+			// they log in to a client certificate.
+			if *clientCA != "" && (*webAddress != "" || *ircAddress != "") {
+				log.Fatal("the web UI and the IRC gateway cannot be served with --client-ca")
+			}
+
 			var creds credentials.TransportCredentials
 			if !*insecure {
-				m := &autocert.Manager{
-					Cache:      autocert.DirCache(*certDir),
-					Prompt:     autocert.AcceptTOS,
-					HostPolicy: autocert.HostWhitelist(*domain),
+				config := &tls.Config{}
+				if *tlsCert != "" {
+					certs, err := newCertReloader(*tlsCert, *tlsKey)
+					if err != nil {
+						log.Fatal(err)
+					}
+					config.GetCertificate = certs.GetCertificate
+				} else {
+					m, err := newACMEManager(*certDir, *domains, *acmeDirectory, *acmeEmail, *acmeCA)
+					if err != nil {
+						log.Fatal(err)
+					}
+
+					switch *acmeChallenge {
+					case challengeHTTP:
+						go func() {
+							slog.Error("ACME HTTP challenge server terminated", "error", http.ListenAndServe(":http", m.HTTPHandler(nil)))
+						}()
+					case challengeTLSALPN:
+						config.GetConfigForClient = alpnChallengeConfig(m)
+					default:
+						log.Fatalf("unknown acme challenge %q", *acmeChallenge)
+					}
+					config.GetCertificate = m.GetCertificate
+				}
+				if *clientCA != "" {
+					pool, err := loadCertPool(*clientCA)
+					if err != nil {
+						log.Fatal(err)
+					}
+					config.ClientCAs = pool
+					config.ClientAuth = tls.RequireAndVerifyClientCert
 				}
-				go func() {
-					log.Println("autocert manager server terminated. err:", http.ListenAndServe(":http", m.HTTPHandler(nil)))
-				}()
-				creds = credentials.NewTLS(&tls.Config{GetCertificate: m.GetCertificate})
+				creds = credentials.NewTLS(config)
 			}
 
-			if err := runServer(ctx, *address, creds); err != nil {
+			var bridge *web.Bridge
+			if *webAddress != "" {
+				bridgeAddress := *address
+				if !*insecure && *tlsCert == "" {
+					_, port, err := net.SplitHostPort(*address)
+					if err != nil {
+						log.Fatal(err)
+					}
+					bridgeAddress = net.JoinHostPort((*domains)[0], port)
+				}
+				tlsConfig, _, err := clientTLSConfig("", "", *webCAFile, *webServerName)
+				if err != nil {
+					log.Fatal(err)
+				}
+				bridge = web.NewBridge(bridgeAddress, *insecure, tlsConfig)
+			}
+
+			if *adminAddress != "" && *adminToken == "" {
+				log.Fatal("a token is required to serve the admin service")
+			}
+
+			if *streamAddress != "" && *streamToken == "" {
+				log.Fatal("a read token is required to serve the event stream")
+			}
+
+			blobs, err := server.NewBlobStore(*blobDir, int64(*maxUploadSize))
+			if err != nil {
+				log.Fatal(err)
+			}
+			blobs.SetQuota(int64(*uploadQuota), int64(*storageQuota))
+
+			keys, rotation, err := loadServerKeys(*keyFile, *keyDir, *keyRotation, *keyOverlap)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			suites, err := secure.LookupSuites(*cipherSuites)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			shutdown, err := parseShutdown(*shutdownTimeout, *retryAfter)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			settings := serverSettings{
+				motd:          *motd,
+				rateLimit:     *rateLimit,
+				rateBurst:     *rateBurst,
+				logLevel:      *logLevel,
+				maxUploadSize: *maxUploadSize,
+			}
+			overrides.fromEnv()
+			reload := func(current serverSettings) (serverSettings, error) {
+				return reloadServerSettings(cfg.path, current, overrides)
+			}
+
+			if err := runServer(ctx, serverOptions{
+				address:        *address,
+				creds:          creds,
+				blobs:          blobs,
+				keys:           keys,
+				rotation:       rotation,
+				suites:         suites,
+				webAddress:     *webAddress,
+				bridge:         bridge,
+				ircAddress:     *ircAddress,
+				streamAddress:  *streamAddress,
+				streamToken:    *streamToken,
+				metricsAddress: *metricsAddress,
+				shutdown:       shutdown,
+				admin:          adminSettings{address: *adminAddress, token: *adminToken, logLevel: level},
+				settings:       settings,
+				reload:         reload,
+			}); err != nil {
 				cancel()
 				log.Fatal(err)
 			}
@@ -86,24 +428,387 @@ func main() {
 	})
 
 	app.Command("client", "Run server client", func(cmd *cli.Cmd) {
-		serverAddress := app.String(cli.StringOpt{
+		conf := cfg.profile(os.Args[1:])
+		conf.strict()
+		cfg.configOption(cmd)
+		cfg.profileOption(cmd)
+		serverAddress := cmd.String(cli.StringOpt{
 			Name:   "serverAddress",
-			Value:  "localhost:8090",
+			Value:  conf.String("serverAddress", "localhost:8090"),
 			Desc:   "Address of the chat server",
 			EnvVar: "SERVER_ADDRESS",
 		})
-		insecure := app.Bool(cli.BoolOpt{
+		insecure := cmd.Bool(cli.BoolOpt{
 			Name:   "insecure",
-			Value:  true,
+			Value:  conf.Bool("insecure", true),
 			Desc:   "Flag to establish non-secure conn",
 			EnvVar: "INSECURE",
 		})
+		username := cmd.String(cli.StringOpt{
+			Name:   "username",
+			Value:  conf.String("username", ""),
+			Desc:   "Username to log in with, if not the common name of the client certificate (prompted for if empty)",
+			EnvVar: "CHAT_USERNAME",
+		})
+		identityFile := cmd.String(cli.StringOpt{
+			Name:   "identity",
+			Value:  conf.String("identity", ""),
+			Desc:   "Identity file (defaults to identity.pem in the user config directory)",
+			EnvVar: "IDENTITY",
+		})
+		knownServersFile := cmd.String(cli.StringOpt{
+			Name:   "known-servers",
+			Value:  conf.String("known-servers", ""),
+			Desc:   "File pinning the keys of known servers (defaults to known_servers in the user config directory)",
+			EnvVar: "KNOWN_SERVERS",
+		})
+		clientCert := cmd.String(cli.StringOpt{
+			Name:   "client-cert",
+			Value:  conf.String("client-cert", ""),
+			Desc:   "Client certificate to authenticate with, whose common name is the username",
+			EnvVar: "CLIENT_CERT",
+		})
+		clientKey := cmd.String(cli.StringOpt{
+			Name:   "client-key",
+			Value:  conf.String("client-key", ""),
+			Desc:   "Key of the client certificate",
+			EnvVar: "CLIENT_KEY",
+		})
+		caFile := cmd.String(cli.StringOpt{
+			Name:   "ca-file",
+			Value:  conf.String("ca-file", ""),
+			Desc:   "CA file to trust the server certificate with instead of the system roots",
+			EnvVar: "CA_FILE",
+		})
+		serverName := cmd.String(cli.StringOpt{
+			Name:   "server-name",
+			Value:  conf.String("server-name", ""),
+			Desc:   "Name to verify the server certificate against, if not the host of the server address",
+			EnvVar: "SERVER_NAME",
+		})
+		verifiedUsersFile := cmd.String(cli.StringOpt{
+			Name:   "verified-users",
+			Value:  conf.String("verified-users", ""),
+			Desc:   "File of the users verified with /verify (defaults to verified_users in the user config directory)",
+			EnvVar: "VERIFIED_USERS",
+		})
+		cipherSuites := cmd.Strings(cli.StringsOpt{
+			Name:   "cipher-suites",
+			Value:  conf.Strings("cipher-suites", secure.SuiteNames()),
+			Desc:   "Cipher suites to offer the server, in order of preference, repeat for several",
+			EnvVar: "CIPHER_SUITES",
+		})
+		fullScreen := cmd.Bool(cli.BoolOpt{
+			Name:   "tui",
+			Value:  conf.Bool("tui", false),
+			Desc:   "Show the conversation full screen, with the users beside it and the line being typed below it",
+			EnvVar: "CHAT_TUI",
+		})
 
 		cmd.Action = func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			if err := runClient(ctx, *serverAddress, *insecure); err != nil {
+			id, err := loadIdentity(*identityFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			knownServers, err := loadKnownServers(*knownServersFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			verifiedUsers, err := loadVerifiedUsers(*verifiedUsersFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			tlsConfig, certUsername, err := clientTLSConfig(*clientCert, *clientKey, *caFile, *serverName)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			suites, err := secure.LookupSuites(*cipherSuites)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if certUsername != "" {
+				*username = certUsername
+			}
+
+			if err := runClient(ctx, *serverAddress, *insecure, tlsConfig, *username, id, knownServers, verifiedUsers, suites, *fullScreen); err != nil {
+				log.Fatal(err)
+			}
+		}
+	})
+
+	app.Command("trust", "Trust the current key of a server", func(cmd *cli.Cmd) {
+		conf := cfg.profile(os.Args[1:])
+		cfg.configOption(cmd)
+		cfg.profileOption(cmd)
+		serverAddress := cmd.String(cli.StringOpt{
+			Name:   "serverAddress",
+			Value:  conf.String("serverAddress", "localhost:8090"),
+			Desc:   "Address of the chat server",
+			EnvVar: "SERVER_ADDRESS",
+		})
+		insecure := cmd.Bool(cli.BoolOpt{
+			Name:   "insecure",
+			Value:  conf.Bool("insecure", true),
+			Desc:   "Flag to establish non-secure conn",
+			EnvVar: "INSECURE",
+		})
+		knownServersFile := cmd.String(cli.StringOpt{
+			Name:   "known-servers",
+			Value:  conf.String("known-servers", ""),
+			Desc:   "File pinning the keys of known servers (defaults to known_servers in the user config directory)",
+			EnvVar: "KNOWN_SERVERS",
+		})
+		clientCert := cmd.String(cli.StringOpt{
+			Name:   "client-cert",
+			Value:  conf.String("client-cert", ""),
+			Desc:   "Client certificate to authenticate with, whose common name is the username",
+			EnvVar: "CLIENT_CERT",
+		})
+		clientKey := cmd.String(cli.StringOpt{
+			Name:   "client-key",
+			Value:  conf.String("client-key", ""),
+			Desc:   "Key of the client certificate",
+			EnvVar: "CLIENT_KEY",
+		})
+		caFile := cmd.String(cli.StringOpt{
+			Name:   "ca-file",
+			Value:  conf.String("ca-file", ""),
+			Desc:   "CA file to trust the server certificate with instead of the system roots",
+			EnvVar: "CA_FILE",
+		})
+		serverName := cmd.String(cli.StringOpt{
+			Name:   "server-name",
+			Value:  conf.String("server-name", ""),
+			Desc:   "Name to verify the server certificate against, if not the host of the server address",
+			EnvVar: "SERVER_NAME",
+		})
+		fingerprint := cmd.String(cli.StringOpt{
+			Name:  "fingerprint",
+			Value: "",
+			Desc:  "Fingerprint the server key must have, instead of confirming it interactively",
+		})
+
+		cmd.Action = func() {
+			knownServers, err := loadKnownServers(*knownServersFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			tlsConfig, _, err := clientTLSConfig(*clientCert, *clientKey, *caFile, *serverName)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if err := runTrust(context.Background(), *serverAddress, *insecure, tlsConfig, knownServers, *fingerprint); err != nil {
+				log.Fatal(err)
+			}
+		}
+	})
+
+	app.Command("admin", "Operate a running server through its admin service", func(cmd *cli.Cmd) {
+		conf := cfg.section("admin")
+		conf.strict()
+		cfg.configOption(cmd)
+		adminAddress := cmd.String(cli.StringOpt{
+			Name:   "admin-address",
+			Value:  conf.String("admin-address", "localhost:8091"),
+			Desc:   "Address of the admin service of the server",
+			EnvVar: "ADMIN_ADDRESS",
+		})
+		adminToken := cmd.String(cli.StringOpt{
+			Name:   "admin-token",
+			Value:  conf.String("admin-token", ""),
+			Desc:   "Token of the admin service",
+			EnvVar: "ADMIN_TOKEN",
+		})
+		insecure := cmd.Bool(cli.BoolOpt{
+			Name:   "insecure",
+			Value:  conf.Bool("insecure", true),
+			Desc:   "Flag to establish non-secure conn",
+			EnvVar: "INSECURE",
+		})
+		clientCert := cmd.String(cli.StringOpt{
+			Name:   "client-cert",
+			Value:  conf.String("client-cert", ""),
+			Desc:   "Client certificate to present to a server requiring them",
+			EnvVar: "CLIENT_CERT",
+		})
+		clientKey := cmd.String(cli.StringOpt{
+			Name:   "client-key",
+			Value:  conf.String("client-key", ""),
+			Desc:   "Key of the client certificate",
+			EnvVar: "CLIENT_KEY",
+		})
+		caFile := cmd.String(cli.StringOpt{
+			Name:   "ca-file",
+			Value:  conf.String("ca-file", ""),
+			Desc:   "CA file to trust the server certificate with instead of the system roots",
+			EnvVar: "CA_FILE",
+		})
+		serverName := cmd.String(cli.StringOpt{
+			Name:   "server-name",
+			Value:  conf.String("server-name", ""),
+			Desc:   "Name to verify the server certificate against, if not the host of the admin address",
+			EnvVar: "SERVER_NAME",
+		})
+		target := func() adminTarget {
+			return adminTarget{
+				address:    *adminAddress,
+				token:      *adminToken,
+				insecure:   *insecure,
+				clientCert: *clientCert,
+				clientKey:  *clientKey,
+				caFile:     *caFile,
+				serverName: *serverName,
+			}
+		}
+
+		cmd.Command("sessions", "List the sessions logged in", func(cmd *cli.Cmd) {
+			cmd.Action = func() {
+				if err := runAdmin(target(), adminSessions); err != nil {
+					log.Fatal(err)
+				}
+			}
+		})
+
+		cmd.Command("disconnect", "End the session of a user", func(cmd *cli.Cmd) {
+			cmd.Spec = "[--reason] USERNAME"
+			reason := cmd.String(cli.StringOpt{
+				Name:  "reason",
+				Value: "",
+				Desc:  "Reason told to the user",
+			})
+			username := cmd.String(cli.StringArg{
+				Name: "USERNAME",
+				Desc: "User to disconnect",
+			})
+
+			cmd.Action = func() {
+				err := runAdmin(target(), func(ctx context.Context, admin *client.AdminClient) error {
+					return adminDisconnect(ctx, admin, *username, *reason)
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		})
+
+		cmd.Command("announce", "Send a notice, signed by the server, to everyone in a room", func(cmd *cli.Cmd) {
+			cmd.Spec = "[--room] TEXT..."
+			room := cmd.String(cli.StringOpt{
+				Name:  "room",
+				Value: server.PublicRoom,
+				Desc:  "Room to announce to (the public room if empty)",
+			})
+			text := cmd.Strings(cli.StringsArg{
+				Name: "TEXT",
+				Desc: "Text of the announcement",
+			})
+
+			cmd.Action = func() {
+				err := runAdmin(target(), func(ctx context.Context, admin *client.AdminClient) error {
+					return adminAnnounce(ctx, admin, *room, strings.Join(*text, " "))
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		})
+
+		cmd.Command("stats", "Show the statistics of the server", func(cmd *cli.Cmd) {
+			cmd.Action = func() {
+				if err := runAdmin(target(), adminStats); err != nil {
+					log.Fatal(err)
+				}
+			}
+		})
+
+		cmd.Command("settings", "Show the runtime settings of the server, or change those given", func(cmd *cli.Cmd) {
+			cipherSuites := cmd.Strings(cli.StringsOpt{
+				Name:  "cipher-suites",
+				Value: nil,
+				Desc:  "Cipher suites clients may agree on the session key with, repeat for several",
+			})
+			logLevel := cmd.String(cli.StringOpt{
+				Name:  "log-level",
+				Value: "",
+				Desc:  "Lowest level to log (debug, info, warn or error)",
+			})
+			maxUploadSize := cmd.Int(cli.IntOpt{
+				Name:  "max-upload-size",
+				Value: 0,
+				Desc:  "Maximum size in bytes of an uploaded file",
+			})
+
+			cmd.Action = func() {
+				var update *chat.Settings
+				if len(*cipherSuites) > 0 || *logLevel != "" || *maxUploadSize > 0 {
+					update = &chat.Settings{
+						CipherSuites:  *cipherSuites,
+						LogLevel:      *logLevel,
+						MaxUploadSize: int64(*maxUploadSize),
+					}
+				}
+
+				err := runAdmin(target(), func(ctx context.Context, admin *client.AdminClient) error {
+					return adminUpdateSettings(ctx, admin, update)
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		})
+	})
+
+	app.Command("keygen", "Create the identity keys of the client", func(cmd *cli.Cmd) {
+		conf := cfg.profile(os.Args[1:])
+		cfg.configOption(cmd)
+		cfg.profileOption(cmd)
+		identityFile := cmd.String(cli.StringOpt{
+			Name:   "identity",
+			Value:  conf.String("identity", ""),
+			Desc:   "Identity file (defaults to identity.pem in the user config directory)",
+			EnvVar: "IDENTITY",
+		})
+		encrypt := cmd.Bool(cli.BoolOpt{
+			Name:  "passphrase",
+			Value: false,
+			Desc:  "Encrypt the identity with a passphrase",
+		})
+		force := cmd.Bool(cli.BoolOpt{
+			Name:  "force",
+			Value: false,
+			Desc:  "Replace an existing identity",
+		})
+
+		cmd.Action = func() {
+			if err := runKeygen(*identityFile, *force, *encrypt); err != nil {
+				log.Fatal(err)
+			}
+		}
+	})
+
+	app.Command("fingerprint", "Show the fingerprint of the client identity", func(cmd *cli.Cmd) {
+		conf := cfg.profile(os.Args[1:])
+		cfg.configOption(cmd)
+		cfg.profileOption(cmd)
+		identityFile := cmd.String(cli.StringOpt{
+			Name:   "identity",
+			Value:  conf.String("identity", ""),
+			Desc:   "Identity file (defaults to identity.pem in the user config directory)",
+			EnvVar: "IDENTITY",
+		})
+
+		cmd.Action = func() {
+			if err := runFingerprint(*identityFile); err != nil {
 				log.Fatal(err)
 			}
 		}
@@ -114,47 +819,157 @@ func main() {
 	}
 }
 
-func runServer(ctx context.Context, address string, creds credentials.TransportCredentials) error {
+// serverOptions holds what runServer serves the chat server with.
+type serverOptions struct {
+	address        string
+	creds          credentials.TransportCredentials
+	blobs          *server.BlobStore
+	keys           *server.KeyRing
+	rotation       time.Duration
+	suites         []secure.Suite
+	webAddress     string
+	bridge         *web.Bridge
+	ircAddress     string
+	streamAddress  string
+	streamToken    string
+	metricsAddress string
+	shutdown       shutdownSettings
+	admin          adminSettings
+	settings       serverSettings
+	reload         func(serverSettings) (serverSettings, error)
+}
+
+func runServer(ctx context.Context, opts serverOptions) error {
+	settings := opts.settings
 
-	chatServer, err := server.NewServer()
+	chatServer, err := server.NewServer(opts.blobs, opts.keys, opts.suites)
 	if err != nil {
 		return err
 	}
-	serverStop, err := startGRPCServer(address, creds, chatServer)
+	if _, err := settings.apply(nil, chatServer, opts.blobs, opts.admin.logLevel); err != nil {
+		return err
+	}
+
+	unary := []grpc.UnaryServerInterceptor{telemetry.UnaryServerInterceptor}
+	stream := []grpc.StreamServerInterceptor{telemetry.StreamServerInterceptor}
+	if opts.metricsAddress != "" {
+		metricsStop, err := startMetricsServer(opts.metricsAddress, chatServer)
+		if err != nil {
+			return err
+		}
+		defer metricsStop()
+
+		unary = append(unary, chatServer.UnaryMetrics)
+		stream = append(stream, chatServer.StreamMetrics)
+	}
+
+	healthServer := health.NewServer()
+	serverStop, err := startGRPCServer(opts.address, opts.creds, chatServer, healthServer,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
 	if err != nil {
 		return err
 	}
 	defer serverStop()
 
+	if opts.bridge != nil {
+		webStop, err := startHTTPServer("web", opts.webAddress, opts.bridge.Handler())
+		if err != nil {
+			return err
+		}
+		defer webStop()
+		defer opts.bridge.Close()
+	}
+
+	if opts.streamAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/stream", web.NewStream(chatServer, opts.streamToken))
+
+		streamStop, err := startHTTPServer("stream", opts.streamAddress, mux)
+		if err != nil {
+			return err
+		}
+		defer streamStop()
+	}
+
+	if opts.admin.address != "" {
+		adminStop, err := startAdminServer(opts.admin.address, opts.creds, server.NewAdmin(chatServer, opts.admin.token, opts.admin.logLevel))
+		if err != nil {
+			return err
+		}
+		defer adminStop()
+	}
+
+	if opts.ircAddress != "" {
+		ircStop, err := startIRCServer(opts.ircAddress, chatServer)
+		if err != nil {
+			return err
+		}
+		defer ircStop()
+	}
+
 	serverContext, cancel := context.WithCancel(context.Background())
 	go func() {
 		chatServer.Run(serverContext)
+		healthServer.Shutdown()
 	}()
+	if opts.rotation > 0 {
+		go chatServer.RotateKeys(serverContext, opts.rotation)
+	}
 
-	exit := make(chan os.Signal)
+	exit := make(chan os.Signal, 1)
 	signal.Notify(exit, syscall.SIGINT, syscall.SIGTERM)
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
 
-	select {
-	case <-ctx.Done():
-		cancel()
-	case <-exit:
-		cancel()
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-exit:
+			break wait
+		case <-hangup:
+			settings = reloadSettings(settings, opts.reload, chatServer, opts.blobs, opts.admin.logLevel)
+		}
+	}
+
+	// Drain the chat server before the broadcaster and the listeners stop,
+	// so that everyone hears about the restart and nothing queued is lost.
+	slog.Info("Shutting down", "timeout", opts.shutdown.timeout.String())
+	healthServer.Shutdown()
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), opts.shutdown.timeout)
+	if err := chatServer.Shutdown(drainCtx, opts.shutdown.retryAfter); err != nil {
+		slog.Warn("Gave up delivering queued messages", "error", err)
 	}
+	drainCancel()
+	cancel()
 
 	return nil
 }
 
-func runClient(ctx context.Context, serverAddress string, insecure bool) error {
-	fmt.Print("Username: ")
+func runClient(ctx context.Context, serverAddress string, insecure bool, tlsConfig *tls.Config, username string, id *identity.Identity, knownServers *client.KnownServers, verifiedUsers *client.VerifiedUsers, suites []secure.Suite, fullScreen bool) error {
+	if username == "" {
+		fmt.Print("Username: ")
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		username = scanner.Text()
+	}
 
-	username := scanner.Text()
-	client, err := client.NewClient(username, serverAddress, insecure)
+	client, err := client.NewClient(username, serverAddress, insecure, id)
 	if err != nil {
 		return err
 	}
+	client.SetTLSConfig(tlsConfig)
+	client.PinServerKeys(knownServers)
+	client.TrackVerifiedUsers(verifiedUsers)
+	client.SetCipherSuites(suites)
+
+	if fullScreen {
+		return runTUI(client)
+	}
 
 	clientCtx, cancel := context.WithCancel(context.Background())
 	errs := make(chan error)
@@ -164,7 +979,7 @@ func runClient(ctx context.Context, serverAddress string, insecure bool) error {
 		errs <- client.Run(clientCtx)
 	}()
 
-	exit := make(chan os.Signal)
+	exit := make(chan os.Signal, 1)
 	signal.Notify(exit, syscall.SIGINT, syscall.SIGTERM)
 
 	select {
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/danielcopaciu/chat/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// startMetricsServer serves the metrics of chatServer, along with those of
+// the Go runtime and the process, on /metrics.
+func startMetricsServer(address string, chatServer *server.Server) (func(), error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if err := chatServer.RegisterMetrics(registry); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return startHTTPServer("metrics", address, mux)
+}
@@ -0,0 +1,340 @@
+// Package ratchet implements a Double Ratchet over X25519, in the style of
+// the Signal specification, to give the message channel between two clients
+// forward secrecy: every message is encrypted with a key that is deleted once
+// used, and each round trip mixes in a fresh Diffie-Hellman exchange.
+//
+// All randomness is drawn from the reader a session is created with, so a
+// session fed a deterministic reader produces deterministic output.
+package ratchet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySize is the size of X25519 keys and of the secrets derived from them.
+const KeySize = 32
+
+// maxSkip bounds the number of message keys kept for messages that have not
+// arrived yet.
+const maxSkip = 1000
+
+var (
+	rootInfo      = []byte("chat ratchet root")
+	messageInfo   = []byte("chat ratchet message")
+	handshakeInfo = []byte("chat ratchet handshake")
+)
+
+// KeyPair is an X25519 key pair.
+type KeyPair struct {
+	Private []byte
+	Public  []byte
+}
+
+// Header travels in the clear with every message and tells the recipient
+// how to advance its ratchet.
+type Header struct {
+	PublicKey      []byte
+	PreviousLength uint32
+	Number         uint32
+}
+
+// State is one side of a ratchet session between two parties. It is not
+// safe for concurrent use.
+type State struct {
+	rand io.Reader
+
+	dhs *KeyPair
+	dhr []byte
+
+	rootKey         []byte
+	sendChainKey    []byte
+	receiveChainKey []byte
+
+	sent, received, previousSent uint32
+
+	skipped map[skippedKey][]byte
+}
+
+type skippedKey struct {
+	publicKey string
+	number    uint32
+}
+
+// GenerateKeyPair creates an X25519 key pair from rand.
+func GenerateKeyPair(rand io.Reader) (*KeyPair, error) {
+	private := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand, private); err != nil {
+		return nil, errors.WithMessage(err, "failed to generate ratchet key")
+	}
+
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Private: private, Public: public}, nil
+}
+
+// Initiate starts a session with the owner of remote, their published
+// ratchet key. It returns the session and the ephemeral public key the
+// responder needs to Accept it.
+func Initiate(rand io.Reader, remote []byte) (*State, []byte, error) {
+	ephemeral, err := GenerateKeyPair(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := handshake(ephemeral.Private, remote)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state := &State{
+		rand:    rand,
+		dhr:     remote,
+		skipped: make(map[skippedKey][]byte),
+	}
+	state.dhs, err = GenerateKeyPair(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dh, err := curve25519.X25519(state.dhs.Private, state.dhr)
+	if err != nil {
+		return nil, nil, err
+	}
+	state.rootKey, state.sendChainKey = kdfRoot(secret, dh)
+
+	return state, ephemeral.Public, nil
+}
+
+// Accept answers a session started by a peer against local, our published
+// ratchet key pair, with the given ephemeral key.
+func Accept(rand io.Reader, local *KeyPair, ephemeral []byte) (*State, error) {
+	secret, err := handshake(local.Private, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return &State{
+		rand:    rand,
+		dhs:     local,
+		rootKey: secret,
+		skipped: make(map[skippedKey][]byte),
+	}, nil
+}
+
+// CanEncrypt reports whether the session has a sending chain yet. A
+// responder only has one once it has received a message.
+func (s *State) CanEncrypt() bool {
+	return s.sendChainKey != nil
+}
+
+// Encrypt seals plaintext with the next sending message key, authenticating
+// additionalData and the returned header along with it.
+func (s *State) Encrypt(plaintext, additionalData []byte) (Header, []byte, error) {
+	if s.sendChainKey == nil {
+		return Header{}, nil, errors.New("ratchet session cannot send yet")
+	}
+
+	var messageKey []byte
+	s.sendChainKey, messageKey = kdfChain(s.sendChainKey)
+
+	header := Header{
+		PublicKey:      s.dhs.Public,
+		PreviousLength: s.previousSent,
+		Number:         s.sent,
+	}
+	s.sent++
+
+	ciphertext, err := seal(messageKey, plaintext, header.associate(additionalData))
+	if err != nil {
+		return Header{}, nil, err
+	}
+	return header, ciphertext, nil
+}
+
+// Decrypt opens a message sealed by the other side of the session. The
+// session is left untouched when the message cannot be authenticated.
+func (s *State) Decrypt(header Header, ciphertext, additionalData []byte) ([]byte, error) {
+	ad := header.associate(additionalData)
+
+	key := skippedKey{publicKey: string(header.PublicKey), number: header.Number}
+	if messageKey, ok := s.skipped[key]; ok {
+		plaintext, err := open(messageKey, ciphertext, ad)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.skipped, key)
+		return plaintext, nil
+	}
+
+	next := s.clone()
+	if !bytes.Equal(header.PublicKey, next.dhr) {
+		if err := next.skip(header.PreviousLength); err != nil {
+			return nil, err
+		}
+		if err := next.step(header.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+	if err := next.skip(header.Number); err != nil {
+		return nil, err
+	}
+
+	var messageKey []byte
+	next.receiveChainKey, messageKey = kdfChain(next.receiveChainKey)
+	next.received++
+
+	plaintext, err := open(messageKey, ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+
+	*s = *next
+	return plaintext, nil
+}
+
+// skip stores the receiving message keys up to until for messages that
+// arrive out of order.
+func (s *State) skip(until uint32) error {
+	if s.receiveChainKey == nil {
+		return nil
+	}
+	if until > s.received+maxSkip {
+		return errors.New("too many skipped messages")
+	}
+
+	for s.received < until {
+		var messageKey []byte
+		s.receiveChainKey, messageKey = kdfChain(s.receiveChainKey)
+		s.skipped[skippedKey{publicKey: string(s.dhr), number: s.received}] = messageKey
+		s.received++
+	}
+	return nil
+}
+
+// step performs a Diffie-Hellman ratchet step on a new key of the peer.
+func (s *State) step(remote []byte) error {
+	s.previousSent = s.sent
+	s.sent = 0
+	s.received = 0
+	s.dhr = remote
+
+	dh, err := curve25519.X25519(s.dhs.Private, s.dhr)
+	if err != nil {
+		return err
+	}
+	s.rootKey, s.receiveChainKey = kdfRoot(s.rootKey, dh)
+
+	s.dhs, err = GenerateKeyPair(s.rand)
+	if err != nil {
+		return err
+	}
+
+	dh, err = curve25519.X25519(s.dhs.Private, s.dhr)
+	if err != nil {
+		return err
+	}
+	s.rootKey, s.sendChainKey = kdfRoot(s.rootKey, dh)
+	return nil
+}
+
+func (s *State) clone() *State {
+	next := *s
+	next.skipped = make(map[skippedKey][]byte, len(s.skipped))
+	for k, v := range s.skipped {
+		next.skipped[k] = v
+	}
+	return &next
+}
+
+// associate binds the header to the additional data of a message.
+func (h Header) associate(additionalData []byte) []byte {
+	ad := make([]byte, 0, len(additionalData)+len(h.PublicKey)+8)
+	ad = append(ad, additionalData...)
+	ad = append(ad, h.PublicKey...)
+
+	var counters [8]byte
+	binary.BigEndian.PutUint32(counters[:4], h.PreviousLength)
+	binary.BigEndian.PutUint32(counters[4:], h.Number)
+	return append(ad, counters[:]...)
+}
+
+func handshake(private, public []byte) ([]byte, error) {
+	dh, err := curve25519.X25519(private, public)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid ratchet key")
+	}
+	return derive(dh, nil, handshakeInfo, KeySize), nil
+}
+
+func kdfRoot(rootKey, dh []byte) ([]byte, []byte) {
+	out := derive(dh, rootKey, rootInfo, 2*KeySize)
+	return out[:KeySize], out[KeySize:]
+}
+
+func kdfChain(chainKey []byte) ([]byte, []byte) {
+	mac := hmac.New(sha256.New, chainKey)
+	mac.Write([]byte{0x01})
+	messageKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, chainKey)
+	mac.Write([]byte{0x02})
+	return mac.Sum(nil), messageKey
+}
+
+func seal(messageKey, plaintext, additionalData []byte) ([]byte, error) {
+	aead, nonce, err := messageCipher(messageKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+func open(messageKey, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, nonce, err := messageCipher(messageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("message authentication failed")
+	}
+	return plaintext, nil
+}
+
+// messageCipher expands a message key into an AES-256-GCM key and nonce.
+// Every message key is used exactly once, so the nonce can be derived.
+func messageCipher(messageKey []byte) (cipher.AEAD, []byte, error) {
+	out := derive(messageKey, make([]byte, sha256.Size), messageInfo, KeySize+12)
+
+	block, err := aes.NewCipher(out[:KeySize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, out[KeySize:], nil
+}
+
+func derive(secret, salt, info []byte, length int) []byte {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		panic(err)
+	}
+	return out
+}
@@ -0,0 +1,220 @@
+package ratchet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// testRand returns a deterministic source of randomness for the party seed.
+func testRand(seed string) io.Reader {
+	return hkdf.New(sha256.New, []byte(seed), nil, nil)
+}
+
+// newSession sets up a session from alice, who initiates it, to bob.
+func newSession(t *testing.T) (alice, bob *State) {
+	t.Helper()
+
+	bobKey, err := GenerateKeyPair(testRand("bob key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, ephemeral, err := Initiate(testRand("alice"), bobKey.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err = Accept(testRand("bob"), bobKey, ephemeral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return alice, bob
+}
+
+type sealed struct {
+	header     Header
+	ciphertext []byte
+}
+
+func encrypt(t *testing.T, s *State, plaintext string) sealed {
+	t.Helper()
+	header, ciphertext, err := s.Encrypt([]byte(plaintext), []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealed{header, ciphertext}
+}
+
+func decrypt(t *testing.T, s *State, m sealed, want string) {
+	t.Helper()
+	plaintext, err := s.Decrypt(m.header, m.ciphertext, []byte("ad"))
+	if err != nil {
+		t.Fatalf("decrypting %q: %v", want, err)
+	}
+	if string(plaintext) != want {
+		t.Fatalf("got %q, want %q", plaintext, want)
+	}
+}
+
+// TestChainVectors checks the key derivations against values computed with
+// a separate HMAC and HKDF implementation.
+func TestChainVectors(t *testing.T) {
+	chainKey := bytes.Repeat([]byte{0x01}, KeySize)
+	next, messageKey := kdfChain(chainKey)
+	if got, want := hex.EncodeToString(messageKey), "cc6efb872c237f565ee82df42e4cab00098b13710395e3c6d29f2907d69e4f04"; got != want {
+		t.Errorf("message key %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(next), "c31d79abaf8f2150ee1cfe3dc732eed02a56f79647909bad055a831cb762e9a2"; got != want {
+		t.Errorf("chain key %s, want %s", got, want)
+	}
+
+	root, chain := kdfRoot(bytes.Repeat([]byte{0x02}, KeySize), bytes.Repeat([]byte{0x03}, KeySize))
+	if got, want := hex.EncodeToString(root), "47d666fd7f6a1d923e3c6ac8d6003ba4797e0c4e5ca74b45936dd2e080954f6b"; got != want {
+		t.Errorf("root key %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(chain), "999967ae5342898e8347056bd34da92415baacf4fd147a99d7015f570f0fbc6b"; got != want {
+		t.Errorf("root chain key %s, want %s", got, want)
+	}
+}
+
+// TestSessionVectors pins the output of a session fed fixed randomness, so
+// that a change to the wire format does not go unnoticed.
+func TestSessionVectors(t *testing.T) {
+	alice, bob := newSession(t)
+
+	first := encrypt(t, alice, "hello bob")
+	decrypt(t, bob, first, "hello bob")
+	reply := encrypt(t, bob, "hello alice")
+	decrypt(t, alice, reply, "hello alice")
+
+	vectors := []struct {
+		name      string
+		got, want string
+	}{
+		{"alice ratchet key", hex.EncodeToString(first.header.PublicKey), "49b07728c21904e132b95b339fbcb53eae35d41f4d6f4295d67163213767b505"},
+		{"first ciphertext", hex.EncodeToString(first.ciphertext), "45ec88dfc07cc1f06d8da8ceac6514db0c592ab3cea70adefe"},
+		{"bob ratchet key", hex.EncodeToString(reply.header.PublicKey), "4d401a5cf5778684d1be72609673e55a4531a6bfaea107a11e3129901b0d8742"},
+		{"reply ciphertext", hex.EncodeToString(reply.ciphertext), "9be6b324ffe2ae9a4a53ff8196b61941b479b5f5e895e59b8be07d"},
+		{"root key", hex.EncodeToString(alice.rootKey), "07ea06c13c34374169abb911844b99f24f8fa253240eb49a8f4041392e8d1fa9"},
+	}
+	for _, v := range vectors {
+		if v.got != v.want {
+			t.Errorf("%s %s, want %s", v.name, v.got, v.want)
+		}
+	}
+}
+
+func TestSameChainKeys(t *testing.T) {
+	alice, bob := newSession(t)
+	if bob.CanEncrypt() {
+		t.Fatal("responder can send before receiving")
+	}
+
+	for round := 0; round < 3; round++ {
+		decrypt(t, bob, encrypt(t, alice, "ping"), "ping")
+		if !bytes.Equal(alice.sendChainKey, bob.receiveChainKey) {
+			t.Fatalf("round %d: alice sends on a chain bob does not receive on", round)
+		}
+
+		decrypt(t, alice, encrypt(t, bob, "pong"), "pong")
+		if !bytes.Equal(bob.sendChainKey, alice.receiveChainKey) {
+			t.Fatalf("round %d: bob sends on a chain alice does not receive on", round)
+		}
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	alice, bob := newSession(t)
+
+	var first []sealed
+	for i := 0; i < 4; i++ {
+		first = append(first, encrypt(t, alice, fmt.Sprintf("first %d", i)))
+	}
+	decrypt(t, bob, first[2], "first 2")
+	decrypt(t, bob, first[0], "first 0")
+
+	// Bob's reply starts a new ratchet step, and alice's next messages
+	// arrive before the rest of the first chain.
+	decrypt(t, alice, encrypt(t, bob, "reply"), "reply")
+	second := encrypt(t, alice, "second 0")
+	decrypt(t, bob, second, "second 0")
+
+	decrypt(t, bob, first[3], "first 3")
+	decrypt(t, bob, first[1], "first 1")
+	if len(bob.skipped) != 0 {
+		t.Errorf("%d skipped message keys left after every message arrived", len(bob.skipped))
+	}
+}
+
+func TestSkipLimit(t *testing.T) {
+	alice, bob := newSession(t)
+
+	var messages []sealed
+	for i := 0; i <= maxSkip+1; i++ {
+		messages = append(messages, encrypt(t, alice, fmt.Sprintf("message %d", i)))
+	}
+
+	last := messages[maxSkip+1]
+	if _, err := bob.Decrypt(last.header, last.ciphertext, []byte("ad")); err == nil {
+		t.Fatalf("decrypted a message %d ahead", maxSkip+1)
+	}
+	if len(bob.skipped) != 0 {
+		t.Fatalf("kept %d message keys for a rejected message", len(bob.skipped))
+	}
+
+	decrypt(t, bob, messages[maxSkip], fmt.Sprintf("message %d", maxSkip))
+	if len(bob.skipped) != maxSkip {
+		t.Errorf("kept %d skipped message keys, want %d", len(bob.skipped), maxSkip)
+	}
+	decrypt(t, bob, messages[0], "message 0")
+}
+
+func TestReplay(t *testing.T) {
+	alice, bob := newSession(t)
+
+	first, second := encrypt(t, alice, "first"), encrypt(t, alice, "second")
+	decrypt(t, bob, second, "second")
+	decrypt(t, bob, first, "first")
+
+	for _, m := range []sealed{first, second} {
+		if _, err := bob.Decrypt(m.header, m.ciphertext, []byte("ad")); err == nil {
+			t.Errorf("decrypted message %d again", m.header.Number)
+		}
+	}
+	decrypt(t, bob, encrypt(t, alice, "third"), "third")
+}
+
+func TestTampered(t *testing.T) {
+	alice, bob := newSession(t)
+	decrypt(t, bob, encrypt(t, alice, "hello"), "hello")
+	m := encrypt(t, alice, "attack at dawn")
+
+	otherKey, err := GenerateKeyPair(testRand("mallory"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamper := map[string]func(*sealed){
+		"number":          func(m *sealed) { m.header.Number++ },
+		"previous length": func(m *sealed) { m.header.PreviousLength++ },
+		"public key":      func(m *sealed) { m.header.PublicKey = otherKey.Public },
+		"ciphertext":      func(m *sealed) { m.ciphertext[0] ^= 1 },
+	}
+	for name, change := range tamper {
+		tampered := sealed{m.header, append([]byte(nil), m.ciphertext...)}
+		change(&tampered)
+
+		if _, err := bob.Decrypt(tampered.header, tampered.ciphertext, []byte("ad")); err == nil {
+			t.Errorf("%s: decrypted a tampered message", name)
+		}
+	}
+	if _, err := bob.Decrypt(m.header, m.ciphertext, []byte("other ad")); err == nil {
+		t.Error("decrypted a message with other additional data")
+	}
+
+	// The rejected messages left the session as it was.
+	decrypt(t, bob, m, "attack at dawn")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/danielcopaciu/chat/server"
+	"github.com/pkg/errors"
+)
+
+// serverSettings are the settings of the server that are safe to change
+// while it runs, reloaded from the config file on SIGHUP. Only the settings
+// whose value in the file changed are applied again.
+type serverSettings struct {
+	motd          string
+	rateLimit     int
+	rateBurst     int
+	logLevel      string
+	maxUploadSize int
+}
+
+var defaultServerSettings = serverSettings{
+	rateBurst:     10,
+	logLevel:      "info",
+	maxUploadSize: 10 << 20,
+}
+
+// serverOverrides records the settings given by a flag or an environment
+// variable, which take precedence over the config file when it is reloaded.
+type serverOverrides struct {
+	motd, rateLimit, rateBurst, logLevel, maxUploadSize bool
+}
+
+// fromEnv adds the settings given by an environment variable to the ones
+// given by a flag.
+func (o *serverOverrides) fromEnv() {
+	o.motd = o.motd || os.Getenv("MOTD") != ""
+	o.rateLimit = o.rateLimit || os.Getenv("RATE_LIMIT") != ""
+	o.rateBurst = o.rateBurst || os.Getenv("RATE_BURST") != ""
+	o.logLevel = o.logLevel || os.Getenv("LOG_LEVEL") != ""
+	o.maxUploadSize = o.maxUploadSize || os.Getenv("MAX_UPLOAD_SIZE") != ""
+}
+
+// reloadServerSettings reads the server section of the config file at path
+// again. The settings it leaves out are reset to their defaults, and those
+// in overrides are kept as they are in current.
+func reloadServerSettings(path string, current serverSettings, overrides serverOverrides) (serverSettings, error) {
+	if path == "" {
+		return current, errors.New("no config file to reload")
+	}
+
+	cfg, err := readConfig(path)
+	if err != nil {
+		return current, err
+	}
+
+	conf := cfg.section("server")
+	settings := current
+	if !overrides.motd {
+		settings.motd = conf.String("motd", defaultServerSettings.motd)
+	}
+	if !overrides.rateLimit {
+		settings.rateLimit = conf.Int("rate-limit", defaultServerSettings.rateLimit)
+	}
+	if !overrides.rateBurst {
+		settings.rateBurst = conf.Int("rate-burst", defaultServerSettings.rateBurst)
+	}
+	if !overrides.logLevel {
+		settings.logLevel = conf.String("log-level", defaultServerSettings.logLevel)
+	}
+	if !overrides.maxUploadSize {
+		settings.maxUploadSize = conf.Int("max-upload-size", defaultServerSettings.maxUploadSize)
+	}
+	return settings, cfg.Err()
+}
+
+// apply changes the settings of chatServer, blobs and the log level, once
+// they are all known to be valid. Unless previous is nil, only the settings
+// that differ from it are changed, so that those changed through the admin
+// service since are kept. It returns the names of the settings changed.
+func (s serverSettings) apply(previous *serverSettings, chatServer *server.Server, blobs *server.BlobStore, level *slog.LevelVar) ([]string, error) {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(s.logLevel)); err != nil {
+		return nil, errors.Errorf("invalid log level %q", s.logLevel)
+	}
+	if s.rateLimit < 0 {
+		return nil, errors.New("rate limit cannot be negative")
+	}
+	if s.rateLimit > 0 && s.rateBurst < 1 {
+		return nil, errors.New("rate burst must be at least 1")
+	}
+	if s.maxUploadSize <= 0 {
+		return nil, errors.New("maximum upload size must be positive")
+	}
+
+	var changed []string
+	if previous == nil || s.motd != previous.motd {
+		chatServer.SetMOTD(s.motd)
+		changed = append(changed, "motd")
+	}
+	if previous == nil || s.rateLimit != previous.rateLimit || s.rateBurst != previous.rateBurst {
+		chatServer.SetRateLimit(s.rateLimit, s.rateBurst)
+		changed = append(changed, "rate-limit", "rate-burst")
+	}
+	if previous == nil || s.maxUploadSize != previous.maxUploadSize {
+		blobs.SetMaxUploadSize(int64(s.maxUploadSize))
+		changed = append(changed, "max-upload-size")
+	}
+	if previous == nil || s.logLevel != previous.logLevel {
+		level.Set(logLevel)
+		changed = append(changed, "log-level")
+	}
+	return changed, nil
+}
+
+// reloadSettings applies the settings reload reads that changed since
+// current was read, and returns them as the settings last read.
+func reloadSettings(current serverSettings, reload func(serverSettings) (serverSettings, error), chatServer *server.Server, blobs *server.BlobStore, level *slog.LevelVar) serverSettings {
+	settings, err := reload(current)
+	var changed []string
+	if err == nil {
+		changed, err = settings.apply(&current, chatServer, blobs, level)
+	}
+	if err != nil {
+		slog.Error("Failed to reload settings", "error", err)
+		return current
+	}
+
+	slog.Info("Reloaded settings",
+		"changed", changed,
+		"motd", settings.motd,
+		"rate_limit", settings.rateLimit,
+		"rate_burst", settings.rateBurst,
+		"log_level", settings.logLevel,
+		"max_upload_size", settings.maxUploadSize,
+	)
+	return settings
+}
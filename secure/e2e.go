@@ -0,0 +1,219 @@
+package secure
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var rotationInfo = []byte("chat server key rotation")
+
+// SealFor encrypts plaintext end-to-end: it is sealed with a fresh message
+// key, which is then wrapped with RSA-OAEP for each recipient. Only the
+// holders of the recipients' private keys can read it.
+func SealFor(plaintext, additionalData []byte, recipients map[string]*rsa.PublicKey) ([]byte, map[string][]byte, error) {
+	key, ciphertext, err := SealMessage(plaintext, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make(map[string][]byte, len(recipients))
+	for name, publicKey := range recipients {
+		wrapped, err := WrapKey(publicKey, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[name] = wrapped
+	}
+	return ciphertext, keys, nil
+}
+
+// OpenWith decrypts a ciphertext produced by SealFor using the message key
+// wrapped for the holder of privateKey.
+func OpenWith(privateKey *rsa.PrivateKey, wrappedKey, ciphertext, additionalData []byte) ([]byte, error) {
+	key, err := UnwrapKey(privateKey, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return OpenMessage(key, ciphertext, additionalData)
+}
+
+// SealMessage seals plaintext with a fresh message key and returns the key
+// for the caller to wrap for each recipient.
+func SealMessage(plaintext, additionalData []byte) ([]byte, []byte, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := NewAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err := Seal(aead, plaintext, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, ciphertext, nil
+}
+
+// OpenMessage decrypts a ciphertext produced by SealMessage.
+func OpenMessage(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return Open(aead, ciphertext, additionalData)
+}
+
+// WrapKey encrypts a message key with RSA-OAEP.
+func WrapKey(publicKey *rsa.PublicKey, key []byte) ([]byte, error) {
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to encrypt message key")
+	}
+	return wrapped, nil
+}
+
+// UnwrapKey decrypts a message key wrapped by WrapKey.
+func UnwrapKey(privateKey *rsa.PrivateKey, wrappedKey []byte) ([]byte, error) {
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, wrappedKey, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt message key")
+	}
+	return key, nil
+}
+
+// Sign signs the given parts with RSA-PSS over their SHA-256 digest.
+func Sign(privateKey *rsa.PrivateKey, parts ...[]byte) ([]byte, error) {
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, crypto.SHA256, digest(parts), nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to sign")
+	}
+	return signature, nil
+}
+
+// Verify checks a signature made by Sign over the same parts.
+func Verify(publicKey *rsa.PublicKey, signature []byte, parts ...[]byte) error {
+	if err := rsa.VerifyPSS(publicKey, crypto.SHA256, digest(parts), signature, nil); err != nil {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// SignRotation signs the rotation of the server key from previous to key,
+// both PEM encoded, with the previous private key.
+func SignRotation(privateKey *rsa.PrivateKey, previous, key []byte) ([]byte, error) {
+	return Sign(privateKey, rotationInfo, previous, key)
+}
+
+// VerifyRotation checks that the rotation from previous to key is signed
+// with previous.
+func VerifyRotation(signature, previous, key []byte) error {
+	publicKey, err := ParsePublicKey(previous)
+	if err != nil {
+		return err
+	}
+	return Verify(publicKey, signature, rotationInfo, previous, key)
+}
+
+// MarshalPublicKey encodes an RSA public key as PEM.
+func MarshalPublicKey(publicKey *rsa.PublicKey) ([]byte, error) {
+	data, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: data,
+	}), nil
+}
+
+// ParsePublicKey decodes a PEM encoded RSA public key.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid key")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("invalid key")
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, errors.New("key has an invalid type")
+	}
+}
+
+// KeyFingerprint returns the fingerprint of an RSA public key.
+func KeyFingerprint(publicKey *rsa.PublicKey) (string, error) {
+	data, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(data), nil
+}
+
+// Fingerprint summarises the given public keys as a short string that
+// people can compare out of band.
+func Fingerprint(keys ...[]byte) string {
+	sum := digest(keys)
+	groups := make([]string, 0, len(sum)/2)
+	for i := 0; i < len(sum); i += 2 {
+		groups = append(groups, hex.EncodeToString(sum[i:i+2]))
+	}
+	return strings.Join(groups, " ")
+}
+
+// SafetyNumber derives the number two users compare out of band to check
+// that they see each other's real keys. Each user contributes a half made
+// from their name and identity keys, and the halves are sorted so that both
+// sides arrive at the same number.
+func SafetyNumber(nameA string, keysA [][]byte, nameB string, keysB [][]byte) string {
+	a, b := safetyHalf(nameA, keysA), safetyHalf(nameB, keysB)
+	if b < a {
+		a, b = b, a
+	}
+	return a + " " + b
+}
+
+func safetyHalf(name string, keys [][]byte) string {
+	sum := digest(append([][]byte{[]byte(name)}, keys...))
+	groups := make([]string, 0, 6)
+	for i := 0; i < 30; i += 5 {
+		var n uint64
+		for _, b := range sum[i : i+5] {
+			n = n<<8 | uint64(b)
+		}
+		groups = append(groups, fmt.Sprintf("%05d", n%100000))
+	}
+	return strings.Join(groups, " ")
+}
+
+// digest hashes the parts with their lengths so that they cannot be
+// shifted into one another.
+func digest(parts [][]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(part)))
+		h.Write(length[:])
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
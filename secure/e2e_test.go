@@ -0,0 +1,144 @@
+package secure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSealFor(t *testing.T) {
+	alice, bob, mallory := newRSAKey(t), newRSAKey(t), newRSAKey(t)
+
+	ciphertext, keys, err := SealFor([]byte("hello"), []byte("public"), map[string]*rsa.PublicKey{
+		"alice": &alice.PublicKey,
+		"bob":   &bob.PublicKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d wrapped keys, want 2", len(keys))
+	}
+
+	for name, key := range map[string]*rsa.PrivateKey{"alice": alice, "bob": bob} {
+		plaintext, err := OpenWith(key, keys[name], ciphertext, []byte("public"))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if string(plaintext) != "hello" {
+			t.Errorf("%s read %q", name, plaintext)
+		}
+	}
+
+	if _, err := OpenWith(mallory, keys["bob"], ciphertext, []byte("public")); err == nil {
+		t.Error("opened with a key the message was not wrapped for")
+	}
+	if _, err := OpenWith(bob, keys["bob"], ciphertext, []byte("ops")); err == nil {
+		t.Error("opened with other additional data")
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := OpenWith(bob, keys["bob"], ciphertext, []byte("public")); err == nil {
+		t.Error("opened a tampered ciphertext")
+	}
+}
+
+func TestSign(t *testing.T) {
+	key := newRSAKey(t)
+
+	signature, err := Sign(key, []byte("public"), []byte("notice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(&key.PublicKey, signature, []byte("public"), []byte("notice")); err != nil {
+		t.Fatal(err)
+	}
+	// The parts are length prefixed, so they cannot be shifted into one
+	// another.
+	if err := Verify(&key.PublicKey, signature, []byte("publicn"), []byte("otice")); err == nil {
+		t.Error("verified with the parts split differently")
+	}
+	if err := Verify(&newRSAKey(t).PublicKey, signature, []byte("public"), []byte("notice")); err == nil {
+		t.Error("verified with another key")
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	key, err := NewSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := key.Public().(ed25519.PublicKey)
+
+	signature := SignMessage(key, []byte("alice"), []byte("public"), []byte("hello"))
+	if err := VerifyMessage(publicKey, signature, []byte("alice"), []byte("public"), []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyMessage(publicKey, signature, []byte("mallory"), []byte("public"), []byte("hello")); err == nil {
+		t.Error("verified a message with another sender")
+	}
+	if err := VerifyMessage(publicKey[:16], signature, []byte("alice"), []byte("public"), []byte("hello")); err == nil {
+		t.Error("verified with a truncated key")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	if got, want := Fingerprint([]byte("alice key"), []byte("alice signing key")), "80b9 9726 66bc 8bf1 e68e b2c7 077b 8c74 c497 ef7a 827f bbbc 648b 3492 7316 f48b"; got != want {
+		t.Errorf("fingerprint %q, want %q", got, want)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	previous, next := newRSAKey(t), newRSAKey(t)
+	previousKey, err := MarshalPublicKey(&previous.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextKey, err := MarshalPublicKey(&next.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := SignRotation(previous, previousKey, nextKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRotation(signature, previousKey, nextKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRotation(signature, nextKey, previousKey); err == nil {
+		t.Error("verified the rotation backwards")
+	}
+
+	// A signature over the same keys made for another purpose is no
+	// rotation.
+	other, err := Sign(previous, previousKey, nextKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRotation(other, previousKey, nextKey); err == nil {
+		t.Error("verified a signature without the rotation label")
+	}
+}
+
+func TestSafetyNumber(t *testing.T) {
+	alice := [][]byte{[]byte("alice key"), []byte("alice signing key")}
+	bob := [][]byte{[]byte("bob key"), []byte("bob signing key")}
+	want := "09609 74060 74970 53021 52026 23830 65388 07825 41663 98603 90783 25853"
+	if got := SafetyNumber("alice", alice, "bob", bob); got != want {
+		t.Errorf("safety number %q, want %q", got, want)
+	}
+	if got := SafetyNumber("bob", bob, "alice", alice); got != want {
+		t.Errorf("safety number from the other side %q, want %q", got, want)
+	}
+}
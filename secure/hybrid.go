@@ -0,0 +1,121 @@
+package secure
+
+import (
+	"crypto/cipher"
+	"crypto/mlkem"
+	"crypto/sha256"
+	"io"
+
+	"github.com/danielcopaciu/chat/ratchet"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+var hybridInfo = []byte("chat hybrid session key")
+
+// hybridSuite agrees on the session key with both an ephemeral X25519
+// exchange and ML-KEM-768, and derives it from both shared secrets. The
+// session stays confidential as long as either holds, so recordings of it
+// are protected against a future quantum computer breaking X25519.
+//
+// Keys and shares are the X25519 part followed by the ML-KEM part.
+type hybridSuite struct{}
+
+func (hybridSuite) Name() string {
+	return SuiteX25519MLKEM768AESGCM
+}
+
+func (hybridSuite) GenerateKey() ([]byte, []byte, error) {
+	ephemeralKey, err := NewEphemeralKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decapsulationKey, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to generate ML-KEM key")
+	}
+
+	private := append(ephemeralKey.Private, decapsulationKey.Bytes()...)
+	public := append(ephemeralKey.Public, decapsulationKey.EncapsulationKey().Bytes()...)
+	return private, public, nil
+}
+
+func (hybridSuite) Encapsulate(public []byte) ([]byte, cipher.AEAD, error) {
+	if len(public) != ratchet.KeySize+mlkem.EncapsulationKeySize768 {
+		return nil, nil, errors.New("invalid hybrid key")
+	}
+	peer, encapsulationKeyBytes := public[:ratchet.KeySize], public[ratchet.KeySize:]
+
+	encapsulationKey, err := mlkem.NewEncapsulationKey768(encapsulationKeyBytes)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid ML-KEM key")
+	}
+	kemSecret, ciphertext := encapsulationKey.Encapsulate()
+
+	ephemeralKey, err := NewEphemeralKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dhSecret, err := curve25519.X25519(ephemeralKey.Private, peer)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid ephemeral key")
+	}
+
+	session, err := hybridSessionKey(kemSecret, dhSecret, ciphertext, ephemeralKey.Public, peer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(ephemeralKey.Public, ciphertext...), session, nil
+}
+
+func (hybridSuite) Decapsulate(private, share []byte) (cipher.AEAD, error) {
+	if len(private) != ratchet.KeySize+mlkem.SeedSize {
+		return nil, errors.New("invalid hybrid key")
+	}
+	if len(share) != ratchet.KeySize+mlkem.CiphertextSize768 {
+		return nil, errors.New("invalid hybrid share")
+	}
+	peer, ciphertext := share[:ratchet.KeySize], share[ratchet.KeySize:]
+
+	decapsulationKey, err := mlkem.NewDecapsulationKey768(private[ratchet.KeySize:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid ML-KEM key")
+	}
+
+	kemSecret, err := decapsulationKey.Decapsulate(ciphertext)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid ML-KEM ciphertext")
+	}
+
+	ephemeral := private[:ratchet.KeySize]
+	dhSecret, err := curve25519.X25519(ephemeral, peer)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid ephemeral key")
+	}
+
+	public, err := curve25519.X25519(ephemeral, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return hybridSessionKey(kemSecret, dhSecret, ciphertext, peer, public)
+}
+
+// hybridSessionKey combines both shared secrets into the session key. Like
+// X-Wing, it also binds the ML-KEM ciphertext and both X25519 keys, in the
+// order the server sends them and the client offers them.
+func hybridSessionKey(kemSecret, dhSecret, ciphertext, serverKey, clientKey []byte) (cipher.AEAD, error) {
+	secret := make([]byte, 0, len(kemSecret)+len(dhSecret))
+	secret = append(append(secret, kemSecret...), dhSecret...)
+
+	info := make([]byte, 0, len(hybridInfo)+len(ciphertext)+len(serverKey)+len(clientKey))
+	info = append(append(append(append(info, hybridInfo...), ciphertext...), serverKey...), clientKey...)
+
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), key); err != nil {
+		return nil, err
+	}
+	return NewAEAD(key)
+}
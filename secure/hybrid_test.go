@@ -0,0 +1,32 @@
+package secure
+
+import "testing"
+
+func TestHybridBindsShare(t *testing.T) {
+	suite := hybridSuite{}
+	private, public, err := suite.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	share, server, err := suite.Encapsulate(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Seal(server, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing either half of the share changes the session key.
+	for _, i := range []int{0, len(share) - 1} {
+		tampered := append([]byte(nil), share...)
+		tampered[i] ^= 1
+		client, err := suite.Decapsulate(private, tampered)
+		if err != nil {
+			continue
+		}
+		if _, err := Open(client, sealed, nil); err == nil {
+			t.Errorf("byte %d of the share is not bound to the session key", i)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package secure
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/pkg/errors"
+)
+
+// MaxStampAge bounds how far the timestamp of an envelope may be from the
+// clock of its receiver before the envelope is rejected as stale.
+const MaxStampAge = 2 * time.Minute
+
+const stampSize = 16
+
+// Directions of the stream between a client and the server, bound into the
+// stamps of its envelopes so that an envelope cannot be reflected back to
+// its sender.
+var (
+	ClientToServer = []byte("client to server")
+	ServerToClient = []byte("server to client")
+)
+
+var (
+	// ErrReplayed is returned for an envelope received before.
+	ErrReplayed = errors.New("envelope was replayed")
+	// ErrStale is returned for an envelope whose timestamp is off by more
+	// than MaxStampAge.
+	ErrStale = errors.New("envelope is stale")
+)
+
+// Stamper numbers the envelopes sent in one direction of a session. Each
+// stamp holds a sequence number and a timestamp, sealed with the session
+// key together with the envelope it belongs to.
+type Stamper struct {
+	aead      cipher.AEAD
+	direction []byte
+
+	mtx      sync.Mutex
+	sequence uint64
+}
+
+func NewStamper(aead cipher.AEAD, direction []byte) *Stamper {
+	return &Stamper{aead: aead, direction: direction}
+}
+
+// Stamp returns the stamp of the next envelope, made of parts.
+func (s *Stamper) Stamp(parts ...[]byte) ([]byte, error) {
+	s.mtx.Lock()
+	s.sequence++
+	sequence := s.sequence
+	s.mtx.Unlock()
+
+	stamp := make([]byte, stampSize)
+	binary.BigEndian.PutUint64(stamp, sequence)
+	binary.BigEndian.PutUint64(stamp[8:], uint64(time.Now().UnixNano()))
+	return Seal(s.aead, stamp, stampAssociatedData(s.direction, parts))
+}
+
+// ReplayGuard checks the stamps of the envelopes received in one direction
+// of a session: each must be newer than the last one and recent.
+type ReplayGuard struct {
+	aead      cipher.AEAD
+	direction []byte
+
+	mtx      sync.Mutex
+	sequence uint64
+}
+
+func NewReplayGuard(aead cipher.AEAD, direction []byte) *ReplayGuard {
+	return &ReplayGuard{aead: aead, direction: direction}
+}
+
+// Check verifies the stamp of the envelope made of parts.
+func (g *ReplayGuard) Check(stamp []byte, parts ...[]byte) error {
+	if len(stamp) == 0 {
+		return errors.New("envelope is not stamped")
+	}
+
+	opened, err := Open(g.aead, stamp, stampAssociatedData(g.direction, parts))
+	if err != nil || len(opened) != stampSize {
+		return errors.New("invalid envelope stamp")
+	}
+	sequence := binary.BigEndian.Uint64(opened)
+	timestamp := time.Unix(0, int64(binary.BigEndian.Uint64(opened[8:])))
+
+	if age := time.Since(timestamp); age > MaxStampAge || age < -MaxStampAge {
+		return ErrStale
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if sequence <= g.sequence {
+		return ErrReplayed
+	}
+	g.sequence = sequence
+	return nil
+}
+
+// EnvelopeParts returns the parts of env its stamp covers: the room, the
+// sender, the message, the signature of notices and the key wrapped for
+// each recipient, so that none of them can be swapped for that of another
+// envelope.
+func EnvelopeParts(env *chat.Envelope) [][]byte {
+	parts := [][]byte{[]byte(env.Room), []byte(env.Sender), env.Message, env.Signature}
+
+	recipients := make([]string, 0, len(env.Keys))
+	for recipient := range env.Keys {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+
+	for _, recipient := range recipients {
+		key := env.Keys[recipient]
+		var header []byte
+		if h := key.GetHeader(); h != nil {
+			var lengths [8]byte
+			binary.BigEndian.PutUint32(lengths[:], h.PreviousLength)
+			binary.BigEndian.PutUint32(lengths[4:], h.Number)
+			header = digest([][]byte{h.Session, h.RecipientKey, h.PublicKey, lengths[:]})
+		}
+		parts = append(parts, []byte(recipient), key.GetKey(), header)
+	}
+	return parts
+}
+
+func stampAssociatedData(direction []byte, parts [][]byte) []byte {
+	return digest(append([][]byte{direction}, parts...))
+}
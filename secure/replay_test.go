@@ -0,0 +1,113 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+)
+
+func stampPair(t *testing.T) (*Stamper, *ReplayGuard) {
+	t.Helper()
+
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := NewAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStamper(aead, ClientToServer), NewReplayGuard(aead, ClientToServer)
+}
+
+func testEnvelope() *chat.Envelope {
+	return &chat.Envelope{
+		Room:    "public",
+		Sender:  "alice",
+		Message: []byte("ciphertext"),
+		Keys: map[string]*chat.WrappedKey{
+			"alice": {Key: []byte("key for alice")},
+			"bob": {Key: []byte("key for bob"), Header: &chat.RatchetHeader{
+				Session:   []byte("session"),
+				PublicKey: []byte("ratchet key"),
+				Number:    3,
+			}},
+		},
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	stamper, guard := stampPair(t)
+
+	var stamps [][]byte
+	for i := 0; i < 3; i++ {
+		stamp, err := stamper.Stamp(EnvelopeParts(testEnvelope())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stamps = append(stamps, stamp)
+	}
+
+	if err := guard.Check(stamps[0], EnvelopeParts(testEnvelope())...); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(stamps[0], EnvelopeParts(testEnvelope())...); err != ErrReplayed {
+		t.Errorf("replayed stamp: got %v, want %v", err, ErrReplayed)
+	}
+	// A stamp skipped over is as good as replayed.
+	if err := guard.Check(stamps[2], EnvelopeParts(testEnvelope())...); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(stamps[1], EnvelopeParts(testEnvelope())...); err != ErrReplayed {
+		t.Errorf("late stamp: got %v, want %v", err, ErrReplayed)
+	}
+	if err := guard.Check(nil, EnvelopeParts(testEnvelope())...); err == nil {
+		t.Error("accepted an envelope without a stamp")
+	}
+}
+
+func TestStampCoversEnvelope(t *testing.T) {
+	tamper := map[string]func(*chat.Envelope){
+		"room":      func(env *chat.Envelope) { env.Room = "ops" },
+		"sender":    func(env *chat.Envelope) { env.Sender = "mallory" },
+		"message":   func(env *chat.Envelope) { env.Message = []byte("other") },
+		"signature": func(env *chat.Envelope) { env.Signature = []byte("signature") },
+		"key":       func(env *chat.Envelope) { env.Keys["bob"].Key = []byte("other key") },
+		"header":    func(env *chat.Envelope) { env.Keys["bob"].Header.Number = 4 },
+		"no header": func(env *chat.Envelope) { env.Keys["bob"].Header = nil },
+		"recipient": func(env *chat.Envelope) { env.Keys["carol"] = env.Keys["bob"]; delete(env.Keys, "bob") },
+		"dropped":   func(env *chat.Envelope) { delete(env.Keys, "alice") },
+		"added":     func(env *chat.Envelope) { env.Keys["mallory"] = &chat.WrappedKey{Key: []byte("key")} },
+	}
+
+	for name, change := range tamper {
+		stamper, guard := stampPair(t)
+		stamp, err := stamper.Stamp(EnvelopeParts(testEnvelope())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		env := testEnvelope()
+		change(env)
+		if err := guard.Check(stamp, EnvelopeParts(env)...); err == nil {
+			t.Errorf("%s: accepted a tampered envelope", name)
+		}
+	}
+}
+
+func TestStampDirection(t *testing.T) {
+	stamper, _ := stampPair(t)
+	stamp, err := stamper.Stamp(EnvelopeParts(testEnvelope())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reflected := NewReplayGuard(stamper.aead, ServerToClient)
+	if err := reflected.Check(stamp, EnvelopeParts(testEnvelope())...); err == nil {
+		t.Error("accepted an envelope reflected back to its sender")
+	}
+	if bytes.Equal(stamp[:stamper.aead.NonceSize()], make([]byte, stamper.aead.NonceSize())) {
+		t.Error("stamp has no nonce")
+	}
+}
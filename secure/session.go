@@ -0,0 +1,36 @@
+package secure
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+
+	"github.com/danielcopaciu/chat/ratchet"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+var sessionInfo = []byte("chat session key")
+
+// NewEphemeralKey generates the X25519 key pair one side contributes to a
+// session key. It must be discarded once the session key is derived.
+func NewEphemeralKey() (*ratchet.KeyPair, error) {
+	return ratchet.GenerateKeyPair(rand.Reader)
+}
+
+// sessionSecret derives the session key from our ephemeral private key and
+// the ephemeral public key of the other side. Since neither is ever stored,
+// a recorded session cannot be decrypted with the long-lived keys.
+func sessionSecret(private, peer []byte) ([]byte, error) {
+	shared, err := curve25519.X25519(private, peer)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid ephemeral key")
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, nil, sessionInfo), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
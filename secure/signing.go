@@ -0,0 +1,33 @@
+package secure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+
+	"github.com/pkg/errors"
+)
+
+// NewSigningKey generates the Ed25519 key a client signs its messages with.
+func NewSigningKey() (ed25519.PrivateKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate signing key")
+	}
+	return privateKey, nil
+}
+
+// SignMessage signs the given parts with Ed25519.
+func SignMessage(privateKey ed25519.PrivateKey, parts ...[]byte) []byte {
+	return ed25519.Sign(privateKey, digest(parts))
+}
+
+// VerifyMessage checks a signature made by SignMessage over the same parts.
+func VerifyMessage(publicKey, signature []byte, parts ...[]byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid signing key")
+	}
+	if !ed25519.Verify(publicKey, digest(parts), signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
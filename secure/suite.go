@@ -0,0 +1,150 @@
+package secure
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ProtocolVersion is the version of the login protocol. Version 0 predates
+// cipher suites: the client sends a single X25519 key and the session is
+// encrypted with AES-256-GCM. From version 1 the client offers the suites
+// it supports and the server chooses one of them. From version 2 every
+// envelope on the stream is stamped against replays.
+const ProtocolVersion = 2
+
+// MinProtocolVersion is the oldest version clients accept the answer of a
+// server in: the first in which the server signs the versions offered and
+// chosen along with the suites.
+const MinProtocolVersion = 1
+
+// StampVersion is the first protocol version that stamps envelopes.
+const StampVersion = 2
+
+// Names of the supported cipher suites.
+const (
+	// SuiteX25519AESGCM is the suite of protocol version 0.
+	SuiteX25519AESGCM           = "x25519-aes256gcm"
+	SuiteX25519ChaCha20Poly1305 = "x25519-chacha20poly1305"
+	// SuiteX25519MLKEM768AESGCM adds ML-KEM-768 to the X25519 exchange, so
+	// that recorded sessions stay confidential against quantum computers.
+	SuiteX25519MLKEM768AESGCM = "x25519-mlkem768-aes256gcm"
+)
+
+// Suite is a way for a client and the server to agree on a session key at
+// login, together with the cipher the key is used with. The agreement takes
+// the shape of a key encapsulation: the client offers a public key, the
+// server derives the session key from it along with a share to answer with,
+// and the client derives the same key from that share.
+type Suite interface {
+	Name() string
+	// GenerateKey creates the key pair the client offers the suite with.
+	GenerateKey() (private, public []byte, err error)
+	// Encapsulate derives the session key on the server from the public key
+	// of the client, and returns the share to send back.
+	Encapsulate(public []byte) (share []byte, session cipher.AEAD, err error)
+	// Decapsulate derives the session key on the client from the share of
+	// the server.
+	Decapsulate(private, share []byte) (cipher.AEAD, error)
+}
+
+var suites = []Suite{
+	hybridSuite{},
+	x25519Suite{name: SuiteX25519AESGCM, newAEAD: NewAEAD},
+	x25519Suite{name: SuiteX25519ChaCha20Poly1305, newAEAD: chacha20poly1305.New},
+}
+
+// Suites returns every supported suite, in order of preference.
+func Suites() []Suite {
+	return append([]Suite(nil), suites...)
+}
+
+// SuiteNames returns the names of every supported suite, in order of
+// preference.
+func SuiteNames() []string {
+	names := make([]string, 0, len(suites))
+	for _, suite := range suites {
+		names = append(names, suite.Name())
+	}
+	return names
+}
+
+// LookupSuites returns the suites with names, in the same order.
+func LookupSuites(names []string) ([]Suite, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no cipher suites given")
+	}
+
+	selected := make([]Suite, 0, len(names))
+	for _, name := range names {
+		suite, ok := lookupSuite(name)
+		if !ok {
+			return nil, errors.Errorf("unknown cipher suite %q, expected one of %s", name, strings.Join(SuiteNames(), ", "))
+		}
+		selected = append(selected, suite)
+	}
+	return selected, nil
+}
+
+func lookupSuite(name string) (Suite, bool) {
+	for _, suite := range suites {
+		if suite.Name() == name {
+			return suite, true
+		}
+	}
+	return nil, false
+}
+
+// LoginTranscript returns what the server signs at login: the protocol
+// version the client offered and the one the server chose, the suite it
+// chose, every suite the client offered and both shares of the chosen
+// suite. Signing the whole offer keeps an attacker from forcing an older
+// version or a weaker suite by rewriting the offer or the answer.
+func LoginTranscript(offeredVersion, version uint32, suite string, offered []string, serverShare, clientShare []byte) [][]byte {
+	versions := make([]byte, 8)
+	binary.BigEndian.PutUint32(versions, offeredVersion)
+	binary.BigEndian.PutUint32(versions[4:], version)
+	return [][]byte{versions, []byte(suite), []byte(strings.Join(offered, ",")), serverShare, clientShare}
+}
+
+// x25519Suite agrees on the session key with an ephemeral X25519 exchange.
+type x25519Suite struct {
+	name    string
+	newAEAD func(key []byte) (cipher.AEAD, error)
+}
+
+func (s x25519Suite) Name() string {
+	return s.name
+}
+
+func (s x25519Suite) GenerateKey() ([]byte, []byte, error) {
+	key, err := NewEphemeralKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Private, key.Public, nil
+}
+
+func (s x25519Suite) Encapsulate(public []byte) ([]byte, cipher.AEAD, error) {
+	key, err := NewEphemeralKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.Decapsulate(key.Private, public)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Public, session, nil
+}
+
+func (s x25519Suite) Decapsulate(private, share []byte) (cipher.AEAD, error) {
+	key, err := sessionSecret(private, share)
+	if err != nil {
+		return nil, err
+	}
+	return s.newAEAD(key)
+}
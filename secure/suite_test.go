@@ -0,0 +1,86 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSuites(t *testing.T) {
+	for _, suite := range Suites() {
+		private, public, err := suite.GenerateKey()
+		if err != nil {
+			t.Fatalf("%s: %v", suite.Name(), err)
+		}
+
+		share, server, err := suite.Encapsulate(public)
+		if err != nil {
+			t.Fatalf("%s: %v", suite.Name(), err)
+		}
+		client, err := suite.Decapsulate(private, share)
+		if err != nil {
+			t.Fatalf("%s: %v", suite.Name(), err)
+		}
+
+		sealed, err := Seal(server, []byte("hello"), []byte("public"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plaintext, err := Open(client, sealed, []byte("public")); err != nil || string(plaintext) != "hello" {
+			t.Errorf("%s: both sides do not derive the same key: %q, %v", suite.Name(), plaintext, err)
+		}
+
+		// Another share leads to another key.
+		otherShare, _, err := suite.Encapsulate(public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := suite.Decapsulate(private, otherShare)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(other, sealed, []byte("public")); err == nil {
+			t.Errorf("%s: two shares derive the same key", suite.Name())
+		}
+
+		if _, _, err := suite.Encapsulate(public[:len(public)-1]); err == nil {
+			t.Errorf("%s: encapsulated to a truncated key", suite.Name())
+		}
+	}
+}
+
+func TestLookupSuites(t *testing.T) {
+	selected, err := LookupSuites([]string{SuiteX25519ChaCha20Poly1305, SuiteX25519AESGCM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0].Name() != SuiteX25519ChaCha20Poly1305 || selected[1].Name() != SuiteX25519AESGCM {
+		t.Errorf("got %v", selected)
+	}
+
+	if _, err := LookupSuites([]string{"rot13"}); err == nil {
+		t.Error("looked up an unknown suite")
+	}
+	if _, err := LookupSuites(nil); err == nil {
+		t.Error("looked up no suites")
+	}
+}
+
+func TestLoginTranscript(t *testing.T) {
+	offered := SuiteNames()
+	transcript := func(offeredVersion, version uint32, suite string, offered []string) []byte {
+		return digest(LoginTranscript(offeredVersion, version, suite, offered, []byte("server"), []byte("client")))
+	}
+	want := transcript(ProtocolVersion, ProtocolVersion, SuiteX25519MLKEM768AESGCM, offered)
+
+	changed := map[string][]byte{
+		"offered version": transcript(1, ProtocolVersion, SuiteX25519MLKEM768AESGCM, offered),
+		"version":         transcript(ProtocolVersion, 1, SuiteX25519MLKEM768AESGCM, offered),
+		"suite":           transcript(ProtocolVersion, ProtocolVersion, SuiteX25519AESGCM, offered),
+		"offer":           transcript(ProtocolVersion, ProtocolVersion, SuiteX25519MLKEM768AESGCM, offered[1:]),
+	}
+	for name, got := range changed {
+		if bytes.Equal(got, want) {
+			t.Errorf("the transcript does not cover the %s", name)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/danielcopaciu/chat/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Admin serves the admin service of a server, to operators holding the
+// admin token.
+type Admin struct {
+	server   *Server
+	token    string
+	logLevel *slog.LevelVar
+}
+
+// NewAdmin creates the admin service of server. logLevel is the level of
+// the server logs, changed through the settings.
+func NewAdmin(server *Server, token string, logLevel *slog.LevelVar) *Admin {
+	return &Admin{
+		server:   server,
+		token:    token,
+		logLevel: logLevel,
+	}
+}
+
+// Authorize is a gRPC interceptor rejecting calls that do not carry the
+// admin token as a bearer token.
+func (a *Admin) Authorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !a.authorized(ctx) {
+		return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return handler(ctx, req)
+}
+
+func (a *Admin) authorized(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md["authorization"]
+	if len(values) == 0 {
+		return false
+	}
+
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	return a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+func (a *Admin) ListSessions(ctx context.Context, req *chat.ListSessionsRequest) (*chat.ListSessionsResponse, error) {
+	s := a.server
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+
+	sessions := make([]*chat.SessionInfo, 0, len(s.clients))
+	for username, session := range s.clients {
+		info := &chat.SessionInfo{
+			Username:        username,
+			Id:              session.id,
+			Kind:            "client",
+			Peer:            session.peer,
+			ConnectedAt:     session.since.Unix(),
+			CipherSuite:     session.suite,
+			ProtocolVersion: session.version,
+			QueueDepth:      uint32(len(session.messageBus)),
+			Joined:          session.joined,
+		}
+		if session.gateway {
+			info.Kind = "gateway"
+		}
+		for room := range session.rooms {
+			info.Rooms = append(info.Rooms, room)
+		}
+		sort.Strings(info.Rooms)
+		sessions = append(sessions, info)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Username < sessions[j].Username })
+
+	return &chat.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+func (a *Admin) Disconnect(ctx context.Context, req *chat.DisconnectRequest) (*chat.DisconnectResponse, error) {
+	telemetry.AddFields(ctx, slog.String("username", req.Username))
+	if !a.server.disconnect(ctx, req.Username, req.Reason) {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("%s is not logged in", req.Username))
+	}
+	return &chat.DisconnectResponse{}, nil
+}
+
+func (a *Admin) Announce(ctx context.Context, req *chat.AnnounceRequest) (*chat.AnnounceResponse, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, status.Error(codes.InvalidArgument, "announcement is empty")
+	}
+	a.server.announce(ctx, req.Room, req.Text)
+	return &chat.AnnounceResponse{}, nil
+}
+
+func (a *Admin) Stats(ctx context.Context, req *chat.StatsRequest) (*chat.StatsResponse, error) {
+	s := a.server
+	m := s.metrics
+	resp := &chat.StatsResponse{
+		StartedAt:         s.started.Unix(),
+		BroadcastBacklog:  uint32(len(s.messages)),
+		BroadcastCapacity: uint32(cap(s.messages)),
+		Logins:            total(m.logins.WithLabelValues("success")),
+		FailedLogins:      total(m.logins.WithLabelValues("failure")),
+		MessagesReceived:  total(m.messagesReceived),
+		MessagesSent:      total(m.messagesSent),
+	}
+
+	s.clientMtx.Lock()
+	for _, session := range s.clients {
+		if session.gateway {
+			resp.Gateways++
+		} else {
+			resp.Clients++
+		}
+	}
+	resp.Subscribers = uint32(len(s.subscribers))
+	s.clientMtx.Unlock()
+
+	return resp, nil
+}
+
+func (a *Admin) GetSettings(ctx context.Context, req *chat.GetSettingsRequest) (*chat.Settings, error) {
+	return a.settings(), nil
+}
+
+// UpdateSettings applies the settings that are set, once they are all
+// known to be valid.
+func (a *Admin) UpdateSettings(ctx context.Context, req *chat.UpdateSettingsRequest) (*chat.Settings, error) {
+	settings := req.Settings
+	if settings == nil {
+		return a.settings(), nil
+	}
+
+	var suites []secure.Suite
+	if len(settings.CipherSuites) > 0 {
+		var err error
+		if suites, err = secure.LookupSuites(settings.CipherSuites); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	var level slog.Level
+	if settings.LogLevel != "" {
+		if err := level.UnmarshalText([]byte(settings.LogLevel)); err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid log level %q", settings.LogLevel))
+		}
+	}
+
+	if settings.MaxUploadSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "maximum upload size cannot be negative")
+	}
+
+	if suites != nil {
+		a.server.suitesMtx.Lock()
+		a.server.suites = suites
+		a.server.suitesMtx.Unlock()
+	}
+	if settings.LogLevel != "" {
+		a.logLevel.Set(level)
+	}
+	if settings.MaxUploadSize > 0 {
+		a.server.blobs.SetMaxUploadSize(settings.MaxUploadSize)
+	}
+
+	current := a.settings()
+	slog.InfoContext(ctx, "Updated settings",
+		"cipher_suites", current.CipherSuites,
+		"log_level", current.LogLevel,
+		"max_upload_size", current.MaxUploadSize,
+	)
+	return current, nil
+}
+
+func (a *Admin) settings() *chat.Settings {
+	a.server.suitesMtx.RLock()
+	suites := make([]string, 0, len(a.server.suites))
+	for _, suite := range a.server.suites {
+		suites = append(suites, suite.Name())
+	}
+	a.server.suitesMtx.RUnlock()
+
+	return &chat.Settings{
+		CipherSuites:  suites,
+		LogLevel:      strings.ToLower(a.logLevel.Level().String()),
+		MaxUploadSize: a.server.blobs.MaxUploadSize(),
+	}
+}
+
+// disconnect ends the session of username, telling them reason, and
+// reports whether they were logged in.
+func (s *Server) disconnect(ctx context.Context, username, reason string) bool {
+	s.clientMtx.Lock()
+	session, ok := s.clients[username]
+	if ok {
+		delete(s.clients, username)
+	}
+	s.clientMtx.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	session.close(disconnectMessage(reason))
+
+	slog.InfoContext(ctx, "Disconnected user", "username", username, "session", session.id, "reason", reason)
+	s.announce(ctx, PublicRoom, fmt.Sprintf("%s was disconnected by the server administrator", username))
+	return true
+}
+
+// replacedMessage ends a session replaced by a new login of its user.
+const replacedMessage = "logged in again from another session"
+
+func disconnectMessage(reason string) string {
+	if reason == "" {
+		return "disconnected by the server administrator"
+	}
+	return "disconnected by the server administrator: " + reason
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"regexp"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// usernamePattern is what a username may look like. Usernames are shown
+// in IRC prefixes and notices, so they hold no spaces or control characters.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,31}$`)
+
+// invalidUsername explains the usernames usernamePattern accepts.
+const invalidUsername = "a username is up to 32 letters, digits, '_', '.' or '-', not starting with '.' or '-'"
+
+func validUsername(name string) bool {
+	return usernamePattern.MatchString(name)
+}
+
+// certificateUsername returns the username of a peer authenticated with a
+// client certificate: the common name of its verified certificate. Such a
+// peer cannot act as anyone else, whatever username it declares.
+func certificateUsername(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	return name, name != ""
+}
+
+// username returns the certificate username of the peer if it has one, and
+// declared otherwise.
+func username(ctx context.Context, declared string) string {
+	if name, ok := certificateUsername(ctx); ok {
+		return name
+	}
+	return declared
+}
@@ -0,0 +1,300 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/pkg/errors"
+)
+
+var blobID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// maxNameLength caps the length in bytes of the name of an uploaded file.
+const maxNameLength = 255
+
+var (
+	errBlobTooLarge  = errors.New("file exceeds the maximum upload size")
+	errBlobCorrupt   = errors.New("file does not match its declared size or checksum")
+	errBlobNotFound  = errors.New("file not found")
+	errQuotaExceeded = errors.New("file exceeds your storage quota")
+	errStorageFull   = errors.New("server storage is full")
+)
+
+// BlobStore keeps uploaded files on disk. Each file is stored under its id
+// next to a JSON sidecar holding its info.
+type BlobStore struct {
+	dir string
+	// maxSize is read and changed atomically, as it can be changed while
+	// the server runs.
+	maxSize int64
+
+	// usage counts the bytes stored per owner, and total those stored in
+	// all, including the uploads in progress. Quotas of 0 are unlimited.
+	usageMtx   sync.Mutex
+	usage      map[string]int64
+	total      int64
+	userQuota  int64
+	totalQuota int64
+}
+
+type blobMeta struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	Owner       string `json:"owner"`
+}
+
+func NewBlobStore(dir string, maxSize int64) (*BlobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithMessage(err, "failed to create blob directory")
+	}
+
+	b := &BlobStore{
+		dir:     dir,
+		maxSize: maxSize,
+		usage:   make(map[string]int64),
+	}
+	if err := b.loadUsage(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// loadUsage counts the bytes already stored per owner.
+func (b *BlobStore) loadUsage() error {
+	paths, err := filepath.Glob(filepath.Join(b.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.WithMessage(err, "failed to read file info")
+		}
+		var meta blobMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("failed to read file info %s", path))
+		}
+		b.usage[meta.Owner] += meta.Size
+		b.total += meta.Size
+	}
+	return nil
+}
+
+// SetQuota limits the bytes each user may store to perUser, and those
+// stored in all to total. A quota of 0 lifts the limit.
+func (b *BlobStore) SetQuota(perUser, total int64) {
+	b.usageMtx.Lock()
+	defer b.usageMtx.Unlock()
+
+	b.userQuota = perUser
+	b.totalQuota = total
+}
+
+// reserve accounts for size bytes stored by owner, unless they exceed a
+// quota.
+func (b *BlobStore) reserve(owner string, size int64) error {
+	b.usageMtx.Lock()
+	defer b.usageMtx.Unlock()
+
+	if b.totalQuota > 0 && b.total+size > b.totalQuota {
+		return errStorageFull
+	}
+	if b.userQuota > 0 && b.usage[owner]+size > b.userQuota {
+		return errQuotaExceeded
+	}
+	b.usage[owner] += size
+	b.total += size
+	return nil
+}
+
+func (b *BlobStore) release(owner string, size int64) {
+	b.usageMtx.Lock()
+	defer b.usageMtx.Unlock()
+
+	b.usage[owner] -= size
+	b.total -= size
+}
+
+func (b *BlobStore) MaxUploadSize() int64 {
+	return atomic.LoadInt64(&b.maxSize)
+}
+
+func (b *BlobStore) SetMaxUploadSize(size int64) {
+	atomic.StoreInt64(&b.maxSize, size)
+}
+
+// validate checks the info announced at the start of an upload.
+func (b *BlobStore) validate(info *chat.FileInfo) error {
+	if info == nil || filepath.Base(info.Name) == "." || filepath.Base(info.Name) == string(filepath.Separator) {
+		return errors.New("file name is required")
+	}
+	if info.Length < 0 || info.Length > b.MaxUploadSize() {
+		return errBlobTooLarge
+	}
+	if len(info.Sha256) != sha256.Size {
+		return errors.New("invalid sha256 checksum")
+	}
+	if info.ContentType == "" {
+		info.ContentType = "application/octet-stream"
+	}
+	if _, _, err := mime.ParseMediaType(info.ContentType); err != nil {
+		return errors.New("invalid content type")
+	}
+
+	info.Name = cleanName(info.Name)
+	return nil
+}
+
+// cleanName keeps the base of name, with control and formatting characters
+// replaced and cut to maxNameLength, so that it cannot pass for more than a
+// file name where it is shown.
+func cleanName(name string) string {
+	name = strings.ToValidUTF8(filepath.Base(name), "_")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > maxNameLength {
+		cut := maxNameLength
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return name
+}
+
+// Put stores the content read from r under a new id. The content must match
+// the size and checksum declared in info, which must have been validated,
+// and fit the quotas of owner and of the store.
+func (b *BlobStore) Put(owner string, info *chat.FileInfo, r io.Reader) (id string, err error) {
+	if err := b.reserve(owner, info.Length); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			b.release(owner, info.Length)
+		}
+	}()
+
+	tmp, err := ioutil.TempFile(b.dir, "upload-")
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to store file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	digest := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, digest), io.LimitReader(r, info.Length+1))
+	if err != nil {
+		return "", err
+	}
+	if size != info.Length || !bytes.Equal(digest.Sum(nil), info.Sha256) {
+		return "", errBlobCorrupt
+	}
+	if err := tmp.Close(); err != nil {
+		return "", errors.WithMessage(err, "failed to store file")
+	}
+
+	id, err = newBlobID()
+	if err != nil {
+		return "", err
+	}
+
+	meta, err := json.Marshal(blobMeta{
+		Name:        info.Name,
+		ContentType: info.ContentType,
+		Size:        info.Length,
+		SHA256:      hex.EncodeToString(info.Sha256),
+		Owner:       owner,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(b.metaPath(id), meta, 0600); err != nil {
+		return "", errors.WithMessage(err, "failed to store file")
+	}
+	if err := os.Rename(tmp.Name(), b.blobPath(id)); err != nil {
+		os.Remove(b.metaPath(id))
+		return "", errors.WithMessage(err, "failed to store file")
+	}
+
+	return id, nil
+}
+
+// Get opens the file stored under id.
+func (b *BlobStore) Get(id string) (*chat.FileInfo, io.ReadCloser, error) {
+	if !blobID.MatchString(id) {
+		return nil, nil, errBlobNotFound
+	}
+
+	data, err := ioutil.ReadFile(b.metaPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errBlobNotFound
+		}
+		return nil, nil, err
+	}
+
+	var meta blobMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to read file info")
+	}
+	checksum, err := hex.DecodeString(meta.SHA256)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to read file info")
+	}
+
+	f, err := os.Open(b.blobPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errBlobNotFound
+		}
+		return nil, nil, err
+	}
+
+	return &chat.FileInfo{
+		Name:        meta.Name,
+		ContentType: meta.ContentType,
+		Length:      meta.Size,
+		Sha256:      checksum,
+	}, f, nil
+}
+
+func (b *BlobStore) blobPath(id string) string {
+	return filepath.Join(b.dir, id)
+}
+
+func (b *BlobStore) metaPath(id string) string {
+	return filepath.Join(b.dir, id+".json")
+}
+
+func newBlobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", errors.WithMessage(err, "failed to generate file id")
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+)
+
+func TestCleanName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{"a.txt\n[server] everyone must re-login", "a.txt_[server] everyone must re-login"},
+		{"invoice\u202efdp.exe", "invoice_fdp.exe"},
+		{"bad\xffutf8", "bad_utf8"},
+	}
+	for _, test := range tests {
+		if got := cleanName(test.name); got != test.want {
+			t.Errorf("cleanName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	long := cleanName(strings.Repeat("é", maxNameLength))
+	if len(long) > maxNameLength || !utf8.ValidString(long) {
+		t.Errorf("cleanName kept %d bytes, valid UTF-8 %v", len(long), utf8.ValidString(long))
+	}
+}
+
+func putBlob(b *BlobStore, owner string, content []byte) error {
+	sum := sha256.Sum256(content)
+	info := &chat.FileInfo{Name: "file", Length: int64(len(content)), Sha256: sum[:]}
+	if err := b.validate(info); err != nil {
+		return err
+	}
+	_, err := b.Put(owner, info, bytes.NewReader(content))
+	return err
+}
+
+func TestBlobQuota(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewBlobStore(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetQuota(100, 150)
+
+	if err := putBlob(b, "alice", make([]byte, 101)); err != errBlobTooLarge {
+		t.Errorf("got %v, want %v", err, errBlobTooLarge)
+	}
+	if err := putBlob(b, "alice", make([]byte, 80)); err != nil {
+		t.Fatal(err)
+	}
+	if err := putBlob(b, "alice", make([]byte, 30)); err != errQuotaExceeded {
+		t.Errorf("got %v, want %v", err, errQuotaExceeded)
+	}
+	if err := putBlob(b, "bob", make([]byte, 80)); err != errStorageFull {
+		t.Errorf("got %v, want %v", err, errStorageFull)
+	}
+
+	// A corrupt upload gives its space back.
+	info := &chat.FileInfo{Name: "file", Length: 60, Sha256: make([]byte, sha256.Size)}
+	if _, err := b.Put("bob", info, bytes.NewReader(make([]byte, 60))); err != errBlobCorrupt {
+		t.Errorf("got %v, want %v", err, errBlobCorrupt)
+	}
+	if err := putBlob(b, "bob", make([]byte, 70)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The usage of the files already stored counts after a restart.
+	b, err = NewBlobStore(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetQuota(100, 0)
+	if err := putBlob(b, "alice", make([]byte, 30)); err != errQuotaExceeded {
+		t.Errorf("got %v, want %v", err, errQuotaExceeded)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Keys lists the public key of every user, so that clients can encrypt
+// messages for one another. Users connected through a gateway are listed
+// with the server key.
+func (s *Server) Keys(ctx context.Context, req *chat.KeysRequest) (*chat.KeysResponse, error) {
+	if _, _, err := s.session(ctx); err != nil {
+		return nil, err
+	}
+
+	serverKey, err := secure.MarshalPublicKey(&s.keys.Current().PublicKey)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list keys")
+	}
+
+	s.clientMtx.Lock()
+	keys := make([]*chat.PublicKey, 0, len(s.clients))
+	for username, session := range s.clients {
+		if session.gateway {
+			keys = append(keys, &chat.PublicKey{Username: username, Key: serverKey, Gateway: true})
+			continue
+		}
+
+		key, err := secure.MarshalPublicKey(session.clientKey)
+		if err != nil {
+			s.clientMtx.Unlock()
+			return nil, status.Error(codes.Internal, "failed to list keys")
+		}
+		keys = append(keys, &chat.PublicKey{
+			Username:            username,
+			Key:                 key,
+			RatchetKey:          session.ratchetKey,
+			RatchetKeySignature: session.ratchetKeySignature,
+			SigningKey:          session.signingKey,
+		})
+	}
+	s.clientMtx.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Username < keys[j].Username })
+	return &chat.KeysResponse{Keys: keys}, nil
+}
+
+// ServerKey returns the current server key, so that clients can decide to
+// trust it before logging in.
+func (s *Server) ServerKey(ctx context.Context, req *chat.ServerKeyRequest) (*chat.ServerKeyResponse, error) {
+	key, err := secure.MarshalPublicKey(&s.keys.Current().PublicKey)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to read server key")
+	}
+	return &chat.ServerKeyResponse{Key: key}, nil
+}
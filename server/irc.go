@@ -0,0 +1,496 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+)
+
+const (
+	ircServerName = "chat"
+
+	// ircPublicChannel is the IRC channel mapped to the public conversation.
+	// Every other channel maps to the room of the same name without the '#'.
+	ircPublicChannel = "#chat"
+)
+
+// IRCGateway lets standard IRC clients take part in the conversation. Each
+// registered nick gets its own Session and each channel maps to a room.
+type IRCGateway struct {
+	server  *Server
+	conns   map[net.Conn]struct{}
+	connMtx sync.Mutex
+}
+
+type ircConn struct {
+	gateway  *IRCGateway
+	conn     net.Conn
+	writeMtx sync.Mutex
+	nick     string
+	user     string
+	session  *Session
+}
+
+func NewIRCGateway(server *Server) *IRCGateway {
+	return &IRCGateway{
+		server: server,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts IRC connections on lis until it is closed.
+func (g *IRCGateway) Serve(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+
+		g.connMtx.Lock()
+		g.conns[conn] = struct{}{}
+		g.connMtx.Unlock()
+
+		go func() {
+			defer func() {
+				g.connMtx.Lock()
+				delete(g.conns, conn)
+				g.connMtx.Unlock()
+			}()
+
+			c := &ircConn{gateway: g, conn: conn}
+			c.serve()
+		}()
+	}
+}
+
+// Close disconnects every IRC client.
+func (g *IRCGateway) Close() {
+	g.connMtx.Lock()
+	defer g.connMtx.Unlock()
+
+	for conn := range g.conns {
+		conn.Close()
+	}
+}
+
+func (c *ircConn) serve() {
+	defer c.conn.Close()
+	defer c.logout()
+
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		command, params := parseIRC(scanner.Text())
+		if command == "" {
+			continue
+		}
+
+		if !c.handle(command, params) {
+			return
+		}
+	}
+}
+
+// handle processes a single command and reports whether the connection
+// should stay open.
+func (c *ircConn) handle(command string, params []string) bool {
+	switch command {
+	case "NICK":
+		if len(params) == 0 {
+			c.reply("431", ":No nickname given")
+			return true
+		}
+		if c.session != nil {
+			c.reply("484", ":Nickname changes are not supported")
+			return true
+		}
+		if !validUsername(params[0]) {
+			c.reply("432", params[0]+" :Erroneous nickname, "+invalidUsername)
+			return true
+		}
+		c.nick = params[0]
+		c.register()
+	case "USER":
+		if len(params) < 4 {
+			c.reply("461", "USER :Not enough parameters")
+			return true
+		}
+		if c.session != nil {
+			c.reply("462", ":You may not reregister")
+			return true
+		}
+		c.user = params[0]
+		c.register()
+	case "PING":
+		token := ircServerName
+		if len(params) > 0 {
+			token = params[0]
+		}
+		c.send(fmt.Sprintf(":%s PONG %s :%s", ircServerName, ircServerName, token))
+	case "QUIT":
+		return false
+	case "JOIN", "PART", "PRIVMSG", "NOTICE", "NAMES", "MOTD":
+		if c.session == nil {
+			c.reply("451", ":You have not registered")
+			return true
+		}
+		c.handleRegistered(command, params)
+	default:
+		c.reply("421", command+" :Unknown command")
+	}
+	return true
+}
+
+func (c *ircConn) handleRegistered(command string, params []string) {
+	switch command {
+	case "JOIN":
+		if len(params) == 0 {
+			c.reply("461", "JOIN :Not enough parameters")
+			return
+		}
+		for _, channel := range strings.Split(params[0], ",") {
+			c.join(channel)
+		}
+	case "PART":
+		if len(params) == 0 {
+			c.reply("461", "PART :Not enough parameters")
+			return
+		}
+		for _, channel := range strings.Split(params[0], ",") {
+			c.part(channel)
+		}
+	case "PRIVMSG", "NOTICE":
+		if len(params) < 2 {
+			if command == "PRIVMSG" {
+				c.reply("412", ":No text to send")
+			}
+			return
+		}
+		c.privmsg(command, params[0], params[1])
+	case "NAMES":
+		if len(params) == 0 {
+			for channel := range c.channels() {
+				c.names(channel)
+			}
+			return
+		}
+		for _, channel := range strings.Split(params[0], ",") {
+			c.names(channel)
+		}
+	case "MOTD":
+		c.motd(c.gateway.server.MOTD())
+	}
+}
+
+// register creates the session once both NICK and USER have been received.
+func (c *ircConn) register() {
+	if c.nick == "" || c.user == "" {
+		return
+	}
+	if c.gateway.server.shuttingDown() {
+		c.send(fmt.Sprintf("ERROR :%s", restartNotice))
+		return
+	}
+
+	session := &Session{
+		id:         newSessionID(),
+		messageBus: make(chan broadcast, sessionQueueSize),
+		gateway:    true,
+		rooms:      make(map[string]bool),
+		done:       make(chan struct{}),
+		peer:       c.conn.RemoteAddr().String(),
+		since:      time.Now(),
+		disconnect: func(reason string) {
+			c.send(fmt.Sprintf("ERROR :Closing link: %s", reason))
+			c.conn.Close()
+		},
+	}
+
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	if _, ok := s.clients[c.nick]; ok {
+		s.clientMtx.Unlock()
+		c.reply("433", c.nick+" :Nickname is already in use")
+		c.nick = ""
+		return
+	}
+	session.limiter = s.newLimiter()
+	s.clients[c.nick] = session
+	motd := s.motd
+	s.clientMtx.Unlock()
+
+	c.session = session
+	go c.deliver(session)
+
+	c.reply("001", fmt.Sprintf(":Welcome to the chat %s", c.prefix()))
+	c.reply("002", fmt.Sprintf(":Your host is %s", ircServerName))
+	c.reply("003", ":This server bridges IRC to the chat")
+	c.reply("004", fmt.Sprintf("%s chat o o", ircServerName))
+	c.motd(motd)
+
+	slog.Info("IRC user registered", "username", c.nick, "session", session.id, "peer", c.conn.RemoteAddr().String())
+	s.announce(context.Background(), PublicRoom, fmt.Sprintf("%s has joined the conversation", c.nick))
+}
+
+func (c *ircConn) logout() {
+	if c.session == nil {
+		return
+	}
+
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	if s.clients[c.nick] == c.session {
+		delete(s.clients, c.nick)
+	}
+	s.clientMtx.Unlock()
+	c.session.end("")
+
+	slog.Info("IRC user left", "username", c.nick, "session", c.session.id)
+	s.announce(context.Background(), PublicRoom, fmt.Sprintf("%s has left the conversation", c.nick))
+}
+
+func (c *ircConn) join(channel string) {
+	room, ok := ircRoom(channel)
+	if !ok {
+		c.reply("403", channel+" :No such channel")
+		return
+	}
+
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	joined := c.session.rooms[room]
+	c.session.rooms[room] = true
+	s.clientMtx.Unlock()
+
+	if joined {
+		return
+	}
+
+	c.send(fmt.Sprintf(":%s JOIN %s", c.prefix(), channel))
+	c.names(channel)
+
+	if room != PublicRoom {
+		s.announce(context.Background(), room, fmt.Sprintf("%s has joined %s", c.nick, channel))
+	}
+}
+
+func (c *ircConn) part(channel string) {
+	room, ok := ircRoom(channel)
+	if !ok {
+		c.reply("403", channel+" :No such channel")
+		return
+	}
+
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	joined := c.session.rooms[room]
+	delete(c.session.rooms, room)
+	s.clientMtx.Unlock()
+
+	if !joined {
+		c.reply("442", channel+" :You're not on that channel")
+		return
+	}
+
+	c.send(fmt.Sprintf(":%s PART %s", c.prefix(), channel))
+
+	if room != PublicRoom {
+		s.announce(context.Background(), room, fmt.Sprintf("%s has left %s", c.nick, channel))
+	}
+}
+
+func (c *ircConn) privmsg(command, target, text string) {
+	room, ok := ircRoom(target)
+	if !ok {
+		if command == "PRIVMSG" {
+			c.reply("401", target+" :Direct messages are not supported")
+		}
+		return
+	}
+
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	joined := c.session.rooms[room]
+	s.clientMtx.Unlock()
+
+	if !joined {
+		if command == "PRIVMSG" {
+			c.reply("404", target+" :Cannot send to channel")
+		}
+		return
+	}
+
+	ctx := context.Background()
+	if !s.allow(ctx, c.nick, c.session, func() { c.notice(throttledNotice) }) {
+		return
+	}
+
+	s.publish(ctx, room, &chat.Message{Sender: c.nick, Value: text})
+}
+
+// motd sends the message of the day, or where to join the conversation if
+// there is none.
+func (c *ircConn) motd(motd string) {
+	if motd == "" {
+		c.reply("422", fmt.Sprintf(":Join %s to take part in the conversation", ircPublicChannel))
+		return
+	}
+
+	c.reply("375", fmt.Sprintf(":- %s Message of the day -", ircServerName))
+	for _, line := range strings.Split(motd, "\n") {
+		c.reply("372", ":- "+strings.TrimRight(line, "\r"))
+	}
+	c.reply("376", ":End of /MOTD command")
+}
+
+// notice tells the user something from the server.
+func (c *ircConn) notice(text string) {
+	c.send(fmt.Sprintf(":%s NOTICE %s :%s", ircServerName, c.nick, text))
+}
+
+func (c *ircConn) names(channel string) {
+	room, ok := ircRoom(channel)
+	if !ok {
+		c.reply("366", channel+" :End of /NAMES list")
+		return
+	}
+
+	s := c.gateway.server
+	var nicks []string
+	s.clientMtx.Lock()
+	for username, session := range s.clients {
+		if session.rooms[room] {
+			nicks = append(nicks, username)
+		}
+	}
+	s.clientMtx.Unlock()
+	sort.Strings(nicks)
+
+	if len(nicks) > 0 {
+		c.reply("353", fmt.Sprintf("= %s :%s", channel, strings.Join(nicks, " ")))
+	}
+	c.reply("366", channel+" :End of /NAMES list")
+}
+
+func (c *ircConn) channels() map[string]struct{} {
+	s := c.gateway.server
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+
+	channels := make(map[string]struct{}, len(c.session.rooms))
+	for room := range c.session.rooms {
+		channels[ircChannel(room)] = struct{}{}
+	}
+	return channels
+}
+
+// deliver writes the messages of the rooms the session joined to the
+// connection until the session ends.
+func (c *ircConn) deliver(session *Session) {
+	for {
+		select {
+		case <-session.done:
+			return
+		case b := <-session.messageBus:
+			channel := ircChannel(b.room)
+			msg := b.message
+			if msg == nil {
+				msg = &chat.Message{Sender: b.sender, Value: "(message not encrypted for IRC users)"}
+			}
+			c.gateway.server.metrics.messagesSent.WithLabelValues("irc").Inc()
+
+			for _, line := range strings.Split(msg.Value, "\n") {
+				line = strings.TrimRight(line, "\r")
+				switch {
+				case msg.Sender == "":
+					c.send(fmt.Sprintf(":%s NOTICE %s :%s", ircServerName, channel, line))
+				case msg.Sender != c.nick:
+					c.send(fmt.Sprintf(":%s!%s@%s PRIVMSG %s :%s", msg.Sender, msg.Sender, ircServerName, channel, line))
+				}
+			}
+		}
+	}
+}
+
+func (c *ircConn) prefix() string {
+	return fmt.Sprintf("%s!%s@%s", c.nick, c.user, ircServerName)
+}
+
+func (c *ircConn) reply(code, text string) {
+	nick := c.nick
+	if nick == "" {
+		nick = "*"
+	}
+	c.send(fmt.Sprintf(":%s %s %s %s", ircServerName, code, nick, text))
+}
+
+// send writes line to the client. Carriage returns, line feeds and NULs
+// are replaced, so that no text relayed from elsewhere can end the line and
+// pass for a command of its own.
+func (c *ircConn) send(line string) {
+	line = ircUnsafe.Replace(line)
+
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+
+	if _, err := fmt.Fprintf(c.conn, "%s\r\n", line); err != nil {
+		slog.Warn("Failed to write to IRC connection", "username", c.nick, "error", err)
+	}
+}
+
+var ircUnsafe = strings.NewReplacer("\r", " ", "\n", " ", "\x00", "")
+
+// parseIRC splits a raw IRC line into its command and parameters, dropping
+// the optional prefix. The trailing parameter keeps its spaces.
+func parseIRC(line string) (string, []string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, ":") {
+		i := strings.Index(line, " ")
+		if i < 0 {
+			return "", nil
+		}
+		line = line[i+1:]
+	}
+
+	var trailing *string
+	if i := strings.Index(line, " :"); i >= 0 {
+		t := line[i+2:]
+		trailing = &t
+		line = line[:i]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	params := fields[1:]
+	if trailing != nil {
+		params = append(params, *trailing)
+	}
+	return strings.ToUpper(fields[0]), params
+}
+
+func ircRoom(channel string) (string, bool) {
+	if !strings.HasPrefix(channel, "#") || len(channel) < 2 {
+		return "", false
+	}
+	if channel == ircPublicChannel {
+		return PublicRoom, true
+	}
+	return strings.TrimPrefix(channel, "#"), true
+}
+
+func ircChannel(room string) string {
+	if room == PublicRoom {
+		return ircPublicChannel
+	}
+	return "#" + room
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	blobs, err := NewBlobStore(filepath.Join(dir, "blobs"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadKeyFile(filepath.Join(dir, "server.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(blobs, keys, secure.Suites())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Run(ctx)
+	t.Cleanup(cancel)
+	return s
+}
+
+// startIRC serves the IRC gateway of s on a loopback port.
+func startIRC(t *testing.T, s *Server) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := NewIRCGateway(s)
+	go gateway.Serve(lis)
+	t.Cleanup(func() {
+		lis.Close()
+		gateway.Close()
+	})
+	return lis.Addr().String()
+}
+
+// startGRPC serves the chat service of s on a loopback port.
+func startGRPC(t *testing.T, s *Server) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	chat.RegisterChatServer(grpcServer, s)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	return lis.Addr().String()
+}
+
+type ircClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialIRC(t *testing.T, address string) *ircClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &ircClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// register logs in as nick and waits for the welcome.
+func (c *ircClient) register(nick string) {
+	c.t.Helper()
+	c.send("NICK " + nick)
+	c.send("USER " + nick + " 0 * :" + nick)
+	c.expect(" 001 " + nick + " ")
+}
+
+func (c *ircClient) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.conn, line+"\r\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// readLine returns the next line from the gateway, without its CRLF.
+func (c *ircClient) readLine() (string, error) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		c.t.Fatalf("line %q does not end with CRLF", line)
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+// expect skips lines until one containing text, and returns it.
+func (c *ircClient) expect(text string) string {
+	c.t.Helper()
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			c.t.Fatalf("waiting for %q: %v", text, err)
+		}
+		if strings.Contains(line, text) {
+			return line
+		}
+	}
+}
+
+func TestIRCRegister(t *testing.T) {
+	s := newTestServer(t)
+	s.SetMOTD("Be nice\nNo spam")
+	c := dialIRC(t, startIRC(t, s))
+
+	c.register("alice")
+	c.expect(" 372 alice :- Be nice")
+	c.expect(" 372 alice :- No spam")
+	c.expect(" 376 alice ")
+
+	c.send("MOTD")
+	c.expect(" 375 alice ")
+	c.expect(" 372 alice :- Be nice")
+
+	c.send("PING token")
+	c.expect("PONG chat :token")
+}
+
+func TestIRCInvalidNick(t *testing.T) {
+	s := newTestServer(t)
+	c := dialIRC(t, startIRC(t, s))
+
+	for _, nick := range []string{"#chat", "-alice", "al:ce", strings.Repeat("a", 33)} {
+		c.send("NICK " + nick)
+		c.expect(" 432 * " + nick + " :Erroneous nickname")
+	}
+
+	c.register("alice")
+}
+
+func TestIRCStripsLineBreaks(t *testing.T) {
+	s := newTestServer(t)
+	address := startIRC(t, s)
+
+	bob := dialIRC(t, address)
+	bob.register("bob")
+	bob.send("JOIN #chat")
+	bob.expect(" 366 bob #chat ")
+
+	s.publish(context.Background(), PublicRoom, &chat.Message{Sender: "mallory", Value: "hi\rJOIN #evil\x00\nbye"})
+	if line := bob.expect("PRIVMSG #chat"); line != ":mallory!mallory@chat PRIVMSG #chat :hi JOIN #evil" {
+		t.Errorf("got %q", line)
+	}
+	if line := bob.expect("PRIVMSG #chat"); line != ":mallory!mallory@chat PRIVMSG #chat :bye" {
+		t.Errorf("got %q", line)
+	}
+}
+
+func TestIRCMessages(t *testing.T) {
+	s := newTestServer(t)
+	address := startIRC(t, s)
+
+	alice := dialIRC(t, address)
+	alice.register("alice")
+	alice.send("JOIN #chat")
+	alice.expect(" 366 alice #chat ")
+
+	bob := dialIRC(t, address)
+	bob.register("bob")
+	bob.send("JOIN #chat")
+	bob.expect(" 366 bob #chat ")
+
+	alice.send("PRIVMSG #chat :hello bob")
+	if line := bob.expect("PRIVMSG"); line != ":alice!alice@chat PRIVMSG #chat :hello bob" {
+		t.Errorf("got %q", line)
+	}
+
+	bob.send("PRIVMSG #ops :hello")
+	bob.expect(" 404 bob #ops ")
+}
+
+func TestLoginReplacesIRCSession(t *testing.T) {
+	s := newTestServer(t)
+	irc := dialIRC(t, startIRC(t, s))
+	irc.register("alice")
+
+	c, err := client.NewClient("alice", startGRPC(t, s), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	irc.expect("ERROR :Closing link: logged in again from another session")
+	for {
+		if _, err := irc.readLine(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("connection still open: %v", err)
+		}
+	}
+
+	s.clientMtx.Lock()
+	session := s.clients["alice"]
+	s.clientMtx.Unlock()
+	if session == nil || session.gateway {
+		t.Error("alice is not logged in through gRPC")
+	}
+}
+
+func TestLoginRejectsInvalidUsername(t *testing.T) {
+	s := newTestServer(t)
+
+	c, err := client.NewClient("alice\r\nQUIT", startGRPC(t, s), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Connect(context.Background())
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
+
+func TestIRCMessagesSigned(t *testing.T) {
+	s := newTestServer(t)
+	irc := dialIRC(t, startIRC(t, s))
+	irc.register("alice")
+
+	c, err := client.NewClient("bob", startGRPC(t, s), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	irc.send("JOIN #chat")
+	irc.expect(" 366 alice #chat ")
+	irc.send("PRIVMSG #chat :hello bob")
+	// A message claiming to come from the gateway user without the
+	// signature of the server.
+	s.queue(broadcast{room: PublicRoom, sender: "alice", message: &chat.Message{Sender: "alice", Value: "forged"}})
+
+	want := map[string]string{
+		"hello bob": "alice (via gateway)",
+		"forged":    "alice (unverified, via gateway)",
+	}
+	for len(want) > 0 {
+		msg, err := c.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, ok := want[msg.Value]
+		if !ok {
+			continue
+		}
+		if msg.Sender != sender {
+			t.Errorf("%q: got sender %q, want %q", msg.Value, msg.Sender, sender)
+		}
+		delete(want, msg.Value)
+	}
+}
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+)
+
+const (
+	keyBits        = 2048
+	keyBlock       = "PRIVATE KEY"
+	keyPrefix      = "server-"
+	keySuffix      = ".pem"
+	rotationBlock  = "KEY ROTATION"
+	rotationPrefix = "rotation-"
+)
+
+// KeyRing holds the RSA keys of the server. The newest key signs and is
+// handed out to clients; the keys it replaced are still accepted for the
+// overlap window after a rotation, so that messages wrapped for them before
+// clients learn about the new key can be read.
+//
+// Every rotation is recorded, signed with the key it replaced, and the
+// records are kept after the keys are removed, so that clients that were
+// offline through a rotation can follow them from the key they pinned.
+type KeyRing struct {
+	dir     string
+	overlap time.Duration
+
+	mtx       sync.RWMutex
+	keys      []*serverKey
+	rotations []*chat.KeyRotation
+}
+
+type serverKey struct {
+	key     *rsa.PrivateKey
+	created time.Time
+	path    string
+}
+
+// LoadKeyFile loads the key in the PEM file at path, generating it on first
+// run. A key ring loaded from a single file cannot be rotated.
+func LoadKeyFile(path string) (*KeyRing, error) {
+	key, err := readKey(path)
+	if os.IsNotExist(errors.Cause(err)) {
+		key, err = generateKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyRing{keys: []*serverKey{{key: key, created: time.Now(), path: path}}}, nil
+}
+
+// LoadKeyDir loads the keys kept in dir, generating the first one if it is
+// empty. Keys retired for longer than overlap are removed.
+func LoadKeyDir(dir string, overlap time.Duration) (*KeyRing, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithMessage(err, "failed to create key directory")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &KeyRing{dir: dir, overlap: overlap}
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasSuffix(name, keySuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		switch {
+		case strings.HasPrefix(name, keyPrefix):
+			created, ok := fileTime(name, keyPrefix)
+			if !ok {
+				continue
+			}
+
+			key, err := readKey(path)
+			if err != nil {
+				return nil, err
+			}
+			k.keys = append(k.keys, &serverKey{key: key, created: created, path: path})
+		case strings.HasPrefix(name, rotationPrefix):
+			if _, ok := fileTime(name, rotationPrefix); !ok {
+				continue
+			}
+
+			// The files come sorted by name, which is by time for the
+			// nanosecond timestamps of the next two centuries.
+			rotation, err := readRotation(path)
+			if err != nil {
+				return nil, err
+			}
+			k.rotations = append(k.rotations, rotation)
+		}
+	}
+	sort.Slice(k.keys, func(i, j int) bool { return k.keys[i].created.After(k.keys[j].created) })
+
+	if len(k.keys) == 0 {
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	k.Prune()
+	return k, nil
+}
+
+// Current returns the key the server signs with.
+func (k *KeyRing) Current() *rsa.PrivateKey {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	return k.keys[0].key
+}
+
+// Created returns when the current key was created.
+func (k *KeyRing) Created() time.Time {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	return k.keys[0].created
+}
+
+// Accepted returns every key that is still accepted, newest first.
+func (k *KeyRing) Accepted() []*rsa.PrivateKey {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+
+	keys := make([]*rsa.PrivateKey, 0, len(k.keys))
+	for i, key := range k.keys {
+		if i > 0 && time.Since(k.keys[i-1].created) > k.overlap {
+			break
+		}
+		keys = append(keys, key.key)
+	}
+	return keys
+}
+
+// Rotations returns the records of the rotations of the key, oldest first.
+func (k *KeyRing) Rotations() []*chat.KeyRotation {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	return append([]*chat.KeyRotation(nil), k.rotations...)
+}
+
+// Rotate generates a new current key and returns it. Unless it is the first
+// key, the rotation is recorded and signed with the key it replaces.
+func (k *KeyRing) Rotate() (*rsa.PrivateKey, error) {
+	if k.dir == "" {
+		return nil, errors.New("keys loaded from a file cannot be rotated")
+	}
+
+	created := time.Now()
+	path := filepath.Join(k.dir, fmt.Sprintf("%s%d%s", keyPrefix, created.UnixNano(), keySuffix))
+	key, err := generateKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if len(k.keys) > 0 {
+		rotation, err := newRotation(k.keys[0].key, key)
+		if err == nil {
+			err = writeRotation(filepath.Join(k.dir, fmt.Sprintf("%s%d%s", rotationPrefix, created.UnixNano(), keySuffix)), rotation)
+		}
+		if err != nil {
+			os.Remove(path)
+			return nil, err
+		}
+		k.rotations = append(k.rotations, rotation)
+	}
+
+	k.keys = append([]*serverKey{{key: key, created: created, path: path}}, k.keys...)
+	return key, nil
+}
+
+// Prune removes the keys whose overlap window has passed.
+func (k *KeyRing) Prune() {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	for i := 1; i < len(k.keys); i++ {
+		if time.Since(k.keys[i-1].created) <= k.overlap {
+			continue
+		}
+
+		for _, key := range k.keys[i:] {
+			if err := os.Remove(key.path); err != nil {
+				slog.Error("Failed to remove retired server key", "path", key.path, "error", err)
+			}
+		}
+		k.keys = k.keys[:i]
+		return
+	}
+}
+
+// fileTime parses the creation time in the name of a file in the key
+// directory.
+func fileTime(name, prefix string) (time.Time, bool) {
+	created, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, prefix), keySuffix), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, created), true
+}
+
+func newRotation(previous, key *rsa.PrivateKey) (*chat.KeyRotation, error) {
+	previousKey, err := secure.MarshalPublicKey(&previous.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := secure.MarshalPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := secure.SignRotation(previous, previousKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chat.KeyRotation{PreviousKey: previousKey, Key: publicKey, Signature: signature}, nil
+}
+
+func readRotation(path string) (*chat.KeyRotation, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != rotationBlock {
+		return nil, errors.Errorf("invalid key rotation in %s", path)
+	}
+
+	var rotation chat.KeyRotation
+	if err := rotation.Unmarshal(block.Bytes); err != nil {
+		return nil, errors.Errorf("invalid key rotation in %s", path)
+	}
+	return &rotation, nil
+}
+
+func writeRotation(path string, rotation *chat.KeyRotation) error {
+	data, err := rotation.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: rotationBlock, Bytes: data}), 0600); err != nil {
+		return errors.WithMessage(err, "failed to save key rotation")
+	}
+	return nil
+}
+
+func readKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != keyBlock {
+		return nil, errors.Errorf("invalid server key in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Errorf("invalid server key in %s", path)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("server key in %s is not an RSA key", path)
+	}
+	return rsaKey, nil
+}
+
+func generateKey(path string) (*rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate server key")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: keyBlock, Bytes: der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return nil, errors.WithMessage(err, "failed to save server key")
+	}
+	return key, nil
+}
+
+// RotateKey replaces the server key and announces the new key to connected
+// clients in a notice signed with the key it replaces.
+func (s *Server) RotateKey() error {
+	previous := s.keys.Current()
+	key, err := s.keys.Rotate()
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := secure.MarshalPublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	s.notify(context.Background(), PublicRoom, &chat.Message{Value: "The server key has been rotated", ServerKey: publicKey}, previous)
+	return nil
+}
+
+// RotateKeys rotates the server key every interval until ctx is done,
+// dropping the keys whose overlap window has passed. A failed rotation is
+// retried a minute later.
+func (s *Server) RotateKeys(ctx context.Context, interval time.Duration) {
+	next := s.keys.Created().Add(interval)
+	for {
+		s.keys.Prune()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+			if err := s.RotateKey(); err != nil {
+				slog.Error("Failed to rotate server key", "error", err)
+				next = time.Now().Add(time.Minute)
+				continue
+			}
+			slog.Info("Rotated server key")
+			next = s.keys.Created().Add(interval)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danielcopaciu/chat/secure"
+)
+
+func TestKeyRotations(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := LoadKeyDir(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotations := keys.Rotations(); len(rotations) != 0 {
+		t.Fatalf("got %d rotations for a new key", len(rotations))
+	}
+
+	previous := keys.Current()
+	for i := 0; i < 2; i++ {
+		if _, err := keys.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys.Prune()
+
+	// The records outlive the keys they were signed with, and are read
+	// back in order.
+	keys, err = LoadKeyDir(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotations := keys.Rotations()
+	if len(rotations) != 2 {
+		t.Fatalf("got %d rotations, want 2", len(rotations))
+	}
+	if len(keys.Accepted()) != 1 {
+		t.Errorf("got %d accepted keys, want 1", len(keys.Accepted()))
+	}
+
+	want, err := secure.MarshalPublicKey(&previous.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, rotation := range rotations {
+		if string(rotation.PreviousKey) != string(want) {
+			t.Errorf("rotation %d does not start at the key before it", i)
+		}
+		if err := secure.VerifyRotation(rotation.Signature, rotation.PreviousKey, rotation.Key); err != nil {
+			t.Errorf("rotation %d: %v", i, err)
+		}
+		want = rotation.Key
+	}
+
+	current, err := secure.MarshalPublicKey(&keys.Current().PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != string(current) {
+		t.Error("the rotations do not lead to the current key")
+	}
+}
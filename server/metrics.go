@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const metricsNamespace = "chat"
+
+// metrics instruments the server. The depths of the queues are read when
+// the metrics are collected rather than tracked as they change.
+type metrics struct {
+	logins            *prometheus.CounterVec
+	messagesReceived  *prometheus.CounterVec
+	messagesSent      *prometheus.CounterVec
+	messagesThrottled prometheus.Counter
+	cryptoDuration    *prometheus.HistogramVec
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+
+	sessions        *prometheus.Desc
+	queueDepth      *prometheus.Desc
+	queueCapacity   *prometheus.Desc
+	backlog         *prometheus.Desc
+	backlogCapacity *prometheus.Desc
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "logins_total",
+			Help:      "Logins by result (success or failure).",
+		}, []string{"result"}),
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_received_total",
+			Help:      "Messages queued for broadcast by source (client, gateway or server).",
+		}, []string{"source"}),
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_sent_total",
+			Help:      "Messages delivered to sessions by transport (grpc or irc).",
+		}, []string{"transport"}),
+		messagesThrottled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_throttled_total",
+			Help:      "Messages dropped for exceeding the rate limit.",
+		}),
+		cryptoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "crypto_duration_seconds",
+			Help:      "Duration of the encryption, decryption and signing done by the server, by operation.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"operation"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_requests_total",
+			Help:      "gRPC calls handled, by method and status code.",
+		}, []string{"method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Duration of gRPC calls by method. Streams last as long as the client stays connected.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+
+		sessions: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "sessions"),
+			"Sessions currently logged in, by kind (client or gateway).",
+			[]string{"kind"}, nil,
+		),
+		queueDepth: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "session_queue_depth"),
+			"Messages waiting in the queue of each session.",
+			[]string{"username"}, nil,
+		),
+		queueCapacity: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "session_queue_capacity"),
+			"Capacity of the queue of each session.",
+			nil, nil,
+		),
+		backlog: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "broadcast_backlog"),
+			"Messages waiting to be broadcast.",
+			nil, nil,
+		),
+		backlogCapacity: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "broadcast_capacity"),
+			"Capacity of the broadcast queue. The broadcaster is backed up when the backlog reaches it.",
+			nil, nil,
+		),
+	}
+}
+
+func (m *metrics) login(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.logins.WithLabelValues(result).Inc()
+}
+
+// timeCrypto records the time elapsed since start for operation.
+func (m *metrics) timeCrypto(operation string, start time.Time) {
+	m.cryptoDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func (m *metrics) request(method string, err error, start time.Time) {
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.requestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// total sums the counters collector holds.
+func total(collector prometheus.Collector) uint64 {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	var sum float64
+	for metric := range metrics {
+		var m dto.Metric
+		if err := metric.Write(&m); err == nil && m.Counter != nil {
+			sum += m.Counter.GetValue()
+		}
+	}
+	return uint64(sum)
+}
+
+// RegisterMetrics registers the metrics of the server with registerer.
+func (s *Server) RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		s.metrics.logins,
+		s.metrics.messagesReceived,
+		s.metrics.messagesSent,
+		s.metrics.messagesThrottled,
+		s.metrics.cryptoDuration,
+		s.metrics.requests,
+		s.metrics.requestDuration,
+		(*queueCollector)(s),
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnaryMetrics is a gRPC interceptor counting unary calls by status code.
+func (s *Server) UnaryMetrics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.metrics.request(info.FullMethod, err, start)
+	return resp, err
+}
+
+// StreamMetrics is a gRPC interceptor counting streams by status code.
+func (s *Server) StreamMetrics(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	s.metrics.request(info.FullMethod, err, start)
+	return err
+}
+
+// queueCollector reports the sessions and the depths of the queues of the
+// server.
+type queueCollector Server
+
+func (c *queueCollector) Describe(descs chan<- *prometheus.Desc) {
+	m := c.metrics
+	for _, desc := range []*prometheus.Desc{m.sessions, m.queueDepth, m.queueCapacity, m.backlog, m.backlogCapacity} {
+		descs <- desc
+	}
+}
+
+func (c *queueCollector) Collect(metrics chan<- prometheus.Metric) {
+	m := c.metrics
+
+	c.clientMtx.Lock()
+	var clients, gateways int
+	for username, session := range c.clients {
+		if session.gateway {
+			gateways++
+		} else {
+			clients++
+		}
+		metrics <- prometheus.MustNewConstMetric(m.queueDepth, prometheus.GaugeValue, float64(len(session.messageBus)), username)
+	}
+	c.clientMtx.Unlock()
+
+	metrics <- prometheus.MustNewConstMetric(m.sessions, prometheus.GaugeValue, float64(clients), "client")
+	metrics <- prometheus.MustNewConstMetric(m.sessions, prometheus.GaugeValue, float64(gateways), "gateway")
+	metrics <- prometheus.MustNewConstMetric(m.queueCapacity, prometheus.GaugeValue, sessionQueueSize)
+	metrics <- prometheus.MustNewConstMetric(m.backlog, prometheus.GaugeValue, float64(len(c.messages)))
+	metrics <- prometheus.MustNewConstMetric(m.backlogCapacity, prometheus.GaugeValue, float64(cap(c.messages)))
+}
@@ -2,15 +2,16 @@ package server
 
 import (
 	"context"
-	"crypto/rand"
+	"crypto/cipher"
+	"crypto/ed25519"
 	"crypto/rsa"
-	"crypto/sha256"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 
@@ -19,186 +20,634 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/danielcopaciu/chat/telemetry"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
-var hash = sha256.New()
-
 type Server struct {
-	clients       map[string]*Session
-	messages      chan chat.Envelope
-	clientMtx     sync.Mutex
-	encryptionKey *rsa.PrivateKey
+	clients     map[string]*Session
+	subscribers map[*subscriber]struct{}
+	messages    chan broadcast
+	clientMtx   sync.Mutex
+	keys        *KeyRing
+	blobs       *BlobStore
+	suites      []secure.Suite
+	suitesMtx   sync.RWMutex
+	metrics     *metrics
+	started     time.Time
+
+	// motd and the rate limit of the sessions are guarded by the client
+	// mutex, as they can be changed while the server runs.
+	motd      string
+	rateLimit int
+	rateBurst int
+
+	// pending counts the messages queued and not yet handed to every
+	// session.
+	pending int64
+
+	// shutdown is closed once logins are refused, closed once the queues
+	// were flushed and the streams should end, and stopped once Run has
+	// returned.
+	shutdown     chan struct{}
+	closed       chan struct{}
+	stopped      chan struct{}
+	shutdownOnce sync.Once
+	closeOnce    sync.Once
+	retryAfter   time.Duration
 }
 
+// PublicRoom is the room of the conversation every session takes part in.
+const PublicRoom = ""
+
+// sessionQueueSize is the number of messages queued for a session before
+// the broadcast waits for it.
+const sessionQueueSize = 100
+
 type Session struct {
-	messageBus chan chat.Envelope
+	id         string
+	messageBus chan broadcast
 	clientKey  *rsa.PublicKey
+	sessionKey cipher.AEAD
+	gateway    bool
+	rooms      map[string]bool
+	done       chan struct{}
+	endOnce    sync.Once
+	// reason is why the session was ended by an administrator, if it was.
+	reason string
+
+	// peer, since, suite and version describe the connection to
+	// administrators. joined is guarded by the client mutex of the server.
+	peer    string
+	since   time.Time
+	suite   string
+	version uint32
+	joined  bool
+
+	// limiter drops the messages sent faster than the rate limit, and
+	// throttled is set once one was dropped, until one goes through.
+	limiter   *rate.Limiter
+	throttled bool
+
+	// disconnect closes the connection of a gateway session.
+	disconnect func(reason string)
+
+	ratchetKey          []byte
+	ratchetKeySignature []byte
+	signingKey          []byte
+
+	// stamper and replays stamp and check the envelopes on the stream of
+	// clients from protocol version 2.
+	stamper *secure.Stamper
+	replays *secure.ReplayGuard
 }
 
-func NewServer() (*Server, error) {
-	encryptionKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return nil, errors.WithMessage(err, "failed to generate server key")
+// end marks the session as gone, so that nothing more is queued for it,
+// with the reason to give the client if the server ended it.
+func (s *Session) end(reason string) {
+	s.endOnce.Do(func() {
+		s.reason = reason
+		close(s.done)
+	})
+}
+
+// close ends the session for reason, closing the connection of gateway
+// sessions, which have no stream to end.
+func (s *Session) close(reason string) {
+	s.end(reason)
+	if s.disconnect != nil {
+		s.disconnect(reason)
 	}
+}
+
+// broadcast is a message on its way to the sessions of a room. Messages
+// between users travel as the end-to-end encrypted envelope of their sender;
+// message holds the plaintext of system notices and of whatever the server
+// reads on behalf of gateway users.
+type broadcast struct {
+	room      string
+	sender    string
+	envelope  *chat.Envelope
+	message   *chat.Message
+	notice    []byte
+	signature []byte
+	// span is the span of the call that queued the message.
+	span trace.SpanContext
+}
 
+// NewServer creates a server that agrees on one of suites with each client.
+func NewServer(blobs *BlobStore, keys *KeyRing, suites []secure.Suite) (*Server, error) {
 	return &Server{
-		clients:       make(map[string]*Session),
-		messages:      make(chan chat.Envelope, 1000),
-		encryptionKey: encryptionKey,
+		clients:     make(map[string]*Session),
+		subscribers: make(map[*subscriber]struct{}),
+		messages:    make(chan broadcast, 1000),
+		keys:        keys,
+		blobs:       blobs,
+		suites:      suites,
+		metrics:     newMetrics(),
+		started:     time.Now(),
+		shutdown:    make(chan struct{}),
+		closed:      make(chan struct{}),
+		stopped:     make(chan struct{}),
 	}, nil
 }
 
 func (s *Server) Login(ctx context.Context, req *chat.LoginRequest) (*chat.LoginResponse, error) {
-	s.clientMtx.Lock()
-	defer s.clientMtx.Unlock()
+	resp, err := s.login(ctx, req)
+	s.metrics.login(err)
+	return resp, err
+}
 
-	session := &Session{
-		messageBus: make(chan chat.Envelope, 100),
+func (s *Server) login(ctx context.Context, req *chat.LoginRequest) (*chat.LoginResponse, error) {
+	if s.shuttingDown() {
+		return nil, s.restarting()
 	}
 
-	block, _ := pem.Decode(req.ClientKey)
-	if block == nil {
-		return nil, errors.New("invalid key received from client")
+	session := &Session{
+		id:         newSessionID(),
+		messageBus: make(chan broadcast, sessionQueueSize),
+		rooms:      map[string]bool{PublicRoom: true},
+		done:       make(chan struct{}),
+		since:      time.Now(),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		session.peer = p.Addr.String()
 	}
 
-	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	clientKey, err := secure.ParsePublicKey(req.ClientKey)
 	if err != nil {
-		return nil, errors.New("invalid key received from client")
+		return nil, errors.WithMessage(err, "invalid key received from client")
 	}
+	session.clientKey = clientKey
 
-	switch pubKey.(type) {
-	case *rsa.PublicKey:
-		session.clientKey = pubKey.(*rsa.PublicKey)
-	default:
-		return nil, errors.New("client key has an invalid type")
+	if err := secure.Verify(clientKey, req.RatchetKeySignature, req.RatchetKey); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "ratchet key is not signed by the client")
 	}
+	session.ratchetKey = req.RatchetKey
+	session.ratchetKeySignature = req.RatchetKeySignature
 
-	s.clients[req.Username] = session
+	if len(req.SigningKey) != ed25519.PublicKeySize {
+		return nil, status.Error(codes.InvalidArgument, "invalid signing key received from client")
+	}
+	session.signingKey = req.SigningKey
 
-	message, err := proto.Marshal(&chat.Message{Value: fmt.Sprintf("%s has joined the conversation", req.Username)})
+	negotiation, err := s.negotiate(req)
 	if err != nil {
 		return nil, err
 	}
 
-	encrpted, err := rsa.EncryptOAEP(hash, rand.Reader, &s.encryptionKey.PublicKey, message, nil)
+	start := time.Now()
+	ephemeralKey, sessionKey, err := negotiation.suite.Encapsulate(negotiation.clientShare)
+	s.metrics.timeCrypto("key_exchange", start)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to encrypt message")
+		return nil, status.Error(codes.InvalidArgument, "invalid ephemeral key received from client")
+	}
+	session.sessionKey = sessionKey
+	session.suite = negotiation.suite.Name()
+	session.version = negotiation.version
+	if negotiation.version >= secure.StampVersion {
+		session.stamper = secure.NewStamper(sessionKey, secure.ServerToClient)
+		session.replays = secure.NewReplayGuard(sessionKey, secure.ClientToServer)
 	}
 
-	s.messages <- chat.Envelope{Message: encrpted}
+	serverKey := s.keys.Current()
+	signature, err := secure.Sign(serverKey, negotiation.transcript(ephemeralKey)...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to sign ephemeral key")
+	}
 
-	publicKey, err := x509.MarshalPKIXPublicKey(&s.encryptionKey.PublicKey)
+	pubBytes, err := secure.MarshalPublicKey(&serverKey.PublicKey)
 	if err != nil {
 		return &chat.LoginResponse{}, status.Error(codes.Internal, "failed to create session for client")
 	}
 
-	pubBytes := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PUBLIC KEY",
-		Bytes: publicKey,
-	})
+	name := username(ctx, req.Username)
+	if !validUsername(name) {
+		return nil, status.Error(codes.InvalidArgument, invalidUsername)
+	}
+	telemetry.AddFields(ctx, slog.String("username", name), slog.String("session", session.id), slog.String("cipher_suite", negotiation.suite.Name()))
+
+	s.clientMtx.Lock()
+	session.limiter = s.newLimiter()
+	previous := s.clients[name]
+	s.clients[name] = session
+	motd := s.motd
+	s.clientMtx.Unlock()
+
+	if previous != nil {
+		previous.close(replacedMessage)
+		slog.InfoContext(ctx, "Replaced session", "previous_session", previous.id)
+	}
+
+	if motd != "" {
+		s.tell(ctx, session, motd)
+	}
+	s.announce(ctx, PublicRoom, fmt.Sprintf("%s has joined the conversation", name))
 
 	return &chat.LoginResponse{
-		ServerKey: pubBytes,
+		ServerKey:             pubBytes,
+		EphemeralKey:          ephemeralKey,
+		EphemeralKeySignature: signature,
+		ProtocolVersion:       negotiation.version,
+		CipherSuite:           negotiation.suite.Name(),
+		KeyRotations:          s.keys.Rotations(),
 	}, nil
 }
 
 func (s *Server) Logout(ctx context.Context, req *chat.LogoutRequest) (*chat.LogoutResponse, error) {
+	name := username(ctx, req.Username)
 	s.clientMtx.Lock()
-	delete(s.clients, req.Username)
+	delete(s.clients, name)
 	s.clientMtx.Unlock()
 
-	message, err := proto.Marshal(&chat.Message{Value: fmt.Sprintf("%s has left the conversation", req.Username)})
-	if err != nil {
-		return nil, err
-	}
-
-	encrpted, err := rsa.EncryptOAEP(hash, rand.Reader, &s.encryptionKey.PublicKey, message, nil)
-	if err != nil {
-		return nil, errors.WithMessage(err, "failed to encrypt message")
-	}
-
-	s.messages <- chat.Envelope{Message: encrpted}
+	telemetry.AddFields(ctx, slog.String("username", name))
+	s.announce(ctx, PublicRoom, fmt.Sprintf("%s has left the conversation", name))
 	return &chat.LogoutResponse{}, nil
 }
 
+// Join delivers the messages of the session until the client leaves, and
+// queues for broadcast the envelopes it sends. Once the server shuts down
+// and the queue of the session is flushed, the stream ends with the delay
+// to reconnect after.
 func (s *Server) Join(stream chat.Chat_JoinServer) error {
-	metadata, ok := metadata.FromIncomingContext(stream.Context())
-	if !ok {
-		return status.Error(codes.Internal, "Failed to read metadata")
-	}
-
-	data := metadata["username"]
-	if len(data) == 0 {
-		return status.Error(codes.Internal, "Unknown user")
+	ctx := stream.Context()
+	username, session, err := s.session(ctx)
+	if err != nil {
+		return err
 	}
 
-	username := data[0]
 	s.clientMtx.Lock()
-	session := s.clients[username]
+	session.joined = true
 	s.clientMtx.Unlock()
 
-	if session == nil {
-		return status.Error(codes.Unauthenticated, "Unauthenticated user")
-	}
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		s.sendMessage(stream, username, session)
+	}()
+	defer func() {
+		s.clientMtx.Lock()
+		session.joined = false
+		s.clientMtx.Unlock()
 
+		session.end("")
+		<-sent
+	}()
+
+	received := make(chan error, 1)
 	go func() {
-		defer close(session.messageBus)
-		s.sendMessage(stream, session)
+		received <- s.receive(stream, username, session)
 	}()
 
+	select {
+	case err := <-received:
+		if err != nil {
+			return err
+		}
+		// The client is done sending but still reads.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-session.done:
+			return disconnected(session)
+		case <-s.closed:
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-session.done:
+		return disconnected(session)
+	case <-s.closed:
+	}
+	return s.restarting()
+}
+
+// disconnected returns the error ending the stream of a session the server
+// ended.
+func disconnected(session *Session) error {
+	return status.Error(codes.PermissionDenied, session.reason)
+}
+
+// receive queues for broadcast the envelopes the client sends on stream,
+// until it closes its side of the stream.
+func (s *Server) receive(stream chat.Chat_JoinServer, username string, session *Session) error {
+	ctx := stream.Context()
 	for {
 		env, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
-				break
+				return nil
+			}
+			return err
+		}
+
+		if session.replays != nil {
+			start := time.Now()
+			err := session.replays.Check(env.Stamp, secure.EnvelopeParts(env)...)
+			s.metrics.timeCrypto("check_stamp", start)
+			if err != nil {
+				slog.WarnContext(ctx, "Rejected envelope", "username", username, "session", session.id, "error", err)
+				return status.Error(codes.InvalidArgument, fmt.Sprintf("envelope rejected: %v", err))
+			}
+		}
+
+		if !s.allow(ctx, username, session, func() { s.tell(ctx, session, throttledNotice) }) {
+			continue
+		}
+
+		env.Room = PublicRoom
+		env.Stamp = nil
+		env.Sender = username
+		env.Signature = nil
+
+		_, span := tracer.Start(ctx, "Receive envelope", trace.WithAttributes(
+			attribute.String("username", username),
+			attribute.String("session", session.id),
+		))
+		s.queue(broadcast{
+			room:     PublicRoom,
+			sender:   username,
+			envelope: env,
+			message:  s.gatewayOpen(env),
+			span:     span.SpanContext(),
+		})
+		span.End()
+		s.metrics.messagesReceived.WithLabelValues("client").Inc()
+		slog.DebugContext(ctx, "Received envelope", "username", username, "session", session.id, "message_trace_id", span.SpanContext().TraceID().String())
+	}
+}
+
+func (s *Server) Users(ctx context.Context, req *chat.UsersRequest) (*chat.UsersResponse, error) {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+
+	usernames := make([]string, 0, len(s.clients))
+	for username := range s.clients {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	return &chat.UsersResponse{Usernames: usernames}, nil
+}
+
+// sendMessage delivers the messages queued for the session until it ends.
+// Once the server shuts down it delivers what is left in the queue and
+// returns.
+func (s *Server) sendMessage(stream chat.Chat_JoinServer, username string, session *Session) error {
+	for {
+		select {
+		case b := <-session.messageBus:
+			if err := s.deliver(stream, username, session, b); err != nil {
+				return err
 			}
+		case <-session.done:
+			return nil
+		case <-s.closed:
+			for {
+				select {
+				case b := <-session.messageBus:
+					if err := s.deliver(stream, username, session, b); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// deliver sends b on the stream of the session of username.
+func (s *Server) deliver(stream chat.Chat_JoinServer, username string, session *Session, b broadcast) error {
+	env, err := s.envelope(b, username, session)
+	if err != nil || env == nil {
+		return err
+	}
+
+	ctx, span := b.startSpan("Deliver", attribute.String("username", username), attribute.String("session", session.id))
+	defer span.End()
+
+	if session.stamper != nil {
+		start := time.Now()
+		env.Stamp, err = session.stamper.Stamp(secure.EnvelopeParts(env)...)
+		s.metrics.timeCrypto("stamp", start)
+		if err != nil {
 			return err
 		}
+	}
 
-		s.messages <- *env
+	err = stream.Send(env)
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
 	}
+	switch status.Code(err) {
+	case codes.OK:
+		s.metrics.messagesSent.WithLabelValues("grpc").Inc()
+		slog.DebugContext(ctx, "Delivered message", "username", username, "session", session.id, "room", b.room, "sender", b.sender)
+	case codes.Unavailable, codes.Canceled, codes.DeadlineExceeded:
+		slog.InfoContext(ctx, "Client connection terminated", "username", username, "session", session.id)
+	}
+	return err
+}
+
+// session looks up the session of the user the request was made for: the
+// user of the client certificate, or the one named in the metadata.
+func (s *Server) session(ctx context.Context) (string, *Session, error) {
+	username, ok := certificateUsername(ctx)
+	if !ok {
+		metadata, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return "", nil, status.Error(codes.Internal, "Failed to read metadata")
+		}
+
+		data := metadata["username"]
+		if len(data) == 0 {
+			return "", nil, status.Error(codes.Internal, "Unknown user")
+		}
+		username = data[0]
+	}
+
+	s.clientMtx.Lock()
+	session := s.clients[username]
+	s.clientMtx.Unlock()
 
-	<-stream.Context().Done()
-	return stream.Context().Err()
+	if session == nil {
+		telemetry.AddFields(ctx, slog.String("username", username))
+		return "", nil, status.Error(codes.Unauthenticated, "Unauthenticated user")
+	}
+	telemetry.AddFields(ctx, slog.String("username", username), slog.String("session", session.id))
+	return username, session, nil
 }
 
-func (s *Server) sendMessage(stream chat.Chat_JoinServer, session *Session) error {
-	for env := range session.messageBus {
-		decrypted, err := rsa.DecryptOAEP(hash, rand.Reader, s.encryptionKey, env.Message, nil)
+// envelope prepares a broadcast for delivery to the session of username. It
+// returns nil when the message was not encrypted for that user.
+func (s *Server) envelope(b broadcast, username string, session *Session) (*chat.Envelope, error) {
+	switch {
+	case b.envelope != nil:
+		key, ok := b.envelope.Keys[username]
+		if !ok {
+			return nil, nil
+		}
+
+		return &chat.Envelope{
+			Message: b.envelope.Message,
+			Room:    b.room,
+			Sender:  b.sender,
+			Keys:    map[string]*chat.WrappedKey{username: key},
+		}, nil
+	case b.notice != nil:
+		start := time.Now()
+		sealed, err := secure.Seal(session.sessionKey, b.notice, []byte(b.room))
+		s.metrics.timeCrypto("seal_notice", start)
 		if err != nil {
-			return errors.WithMessage(err, "failed to decrypt message")
+			return nil, errors.WithMessage(err, "failed to encrypt notice")
 		}
 
-		enrypted, err := rsa.EncryptOAEP(hash, rand.Reader, session.clientKey, decrypted, nil)
+		return &chat.Envelope{
+			Message:   sealed,
+			Room:      b.room,
+			Signature: b.signature,
+		}, nil
+	default:
+		message, err := proto.Marshal(b.message)
 		if err != nil {
-			return errors.WithMessage(err, "failed to encrypt message")
+			return nil, err
 		}
 
-		err = stream.Send(&chat.Envelope{Message: enrypted})
-		if status, ok := status.FromError(err); ok {
-			switch status.Code() {
-			case codes.OK:
-			case codes.Unavailable, codes.Canceled, codes.DeadlineExceeded:
-				log.Print("Client connection terminated")
-				return nil
-			}
+		recipients := map[string]*rsa.PublicKey{username: session.clientKey}
+		start := time.Now()
+		ciphertext, keys, err := secure.SealFor(message, []byte(b.room), recipients)
+		s.metrics.timeCrypto("seal_gateway", start)
+		if err != nil {
+			return nil, err
+		}
+
+		return &chat.Envelope{
+			Message: ciphertext,
+			Room:    b.room,
+			Sender:  b.sender,
+			Keys:    map[string]*chat.WrappedKey{username: {Key: keys[username]}},
+		}, nil
+	}
+}
+
+// gatewayOpen decrypts an end-to-end encrypted envelope when it was also
+// encrypted for a gateway user, whose key the server holds. It returns nil
+// otherwise.
+func (s *Server) gatewayOpen(env *chat.Envelope) *chat.Message {
+	s.clientMtx.Lock()
+	var wrappedKey []byte
+	for username, key := range env.Keys {
+		if session, ok := s.clients[username]; ok && session.gateway {
+			wrappedKey = key.Key
+			break
+		}
+	}
+	s.clientMtx.Unlock()
+
+	if wrappedKey == nil {
+		return nil
+	}
+
+	var decrypted []byte
+	var err error
+	start := time.Now()
+	for _, key := range s.keys.Accepted() {
+		if decrypted, err = secure.OpenWith(key, wrappedKey, env.Message, []byte(env.Room)); err == nil {
+			break
 		}
 	}
-	return nil
+	s.metrics.timeCrypto("open_gateway", start)
+	if err != nil {
+		slog.Error("Failed to read message for gateway users", "sender", env.Sender, "error", err)
+		return nil
+	}
+
+	var msg chat.Message
+	if err := proto.Unmarshal(decrypted, &msg); err != nil {
+		slog.Error("Failed to read message for gateway users", "sender", env.Sender, "error", err)
+		return nil
+	}
+	msg.Sender = env.Sender
+	return &msg
 }
 
+// announce publishes a system notice, signed by the server, to everyone in
+// room.
+func (s *Server) announce(ctx context.Context, room, text string) {
+	s.notify(ctx, room, &chat.Message{Value: text}, s.keys.Current())
+}
+
+// notify publishes msg as a system notice signed with key.
+func (s *Server) notify(ctx context.Context, room string, msg *chat.Message, key *rsa.PrivateKey) {
+	b, err := s.notice(ctx, room, msg, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to announce", "notice", msg.Value, "error", err)
+		return
+	}
+
+	s.queue(b)
+	s.metrics.messagesReceived.WithLabelValues("server").Inc()
+}
+
+// publish queues a message the server sends on behalf of a gateway user,
+// signed with the server key in place of the signing key the user lacks.
+func (s *Server) publish(ctx context.Context, room string, msg *chat.Message) {
+	signature, err := secure.Sign(s.keys.Current(), []byte(msg.Sender), []byte(room), []byte(msg.Value))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to sign gateway message", "sender", msg.Sender, "error", err)
+		return
+	}
+	msg.Signature = signature
+
+	s.queue(broadcast{room: room, sender: msg.Sender, message: msg, span: trace.SpanContextFromContext(ctx)})
+	s.metrics.messagesReceived.WithLabelValues("gateway").Inc()
+}
+
+// Run broadcasts the messages queued to the sessions of their room until
+// ctx is done. Messages queued afterwards are dropped.
 func (s *Server) Run(ctx context.Context) {
-	defer close(s.messages)
+	defer close(s.stopped)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case env := <-s.messages:
+		case b := <-s.messages:
+			ctx, span := b.startSpan("Broadcast", attribute.String("room", b.room), attribute.String("sender", b.sender))
+			b.span = span.SpanContext()
+
+			s.clientMtx.Lock()
+			sessions := make([]*Session, 0, len(s.clients))
 			for _, session := range s.clients {
-				session.messageBus <- env
+				if session.rooms[b.room] {
+					sessions = append(sessions, session)
+				}
+			}
+			for sub := range s.subscribers {
+				if sub.room == b.room {
+					sub.notify(Post{Message: b.message, Envelope: b.envelope})
+				}
 			}
+			s.clientMtx.Unlock()
+
+			for _, session := range sessions {
+				select {
+				case session.messageBus <- b:
+				case <-session.done:
+				}
+			}
+
+			atomic.AddInt64(&s.pending, -1)
+
+			span.SetAttributes(attribute.Int("recipients", len(sessions)))
+			span.End()
+			slog.DebugContext(ctx, "Broadcast message", "room", b.room, "sender", b.sender, "recipients", len(sessions))
 		}
 	}
 }
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+)
+
+func TestConcurrentSends(t *testing.T) {
+	s := newTestServer(t)
+	posts, cancel := s.Subscribe(PublicRoom)
+	defer cancel()
+
+	c, err := client.NewClient("alice", startGRPC(t, s), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	const sends = 50
+	var wg sync.WaitGroup
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := c.Send(fmt.Sprintf("message %d", i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every envelope gets through, none is taken for a replay.
+	timeout := time.After(10 * time.Second)
+	for received := 0; received < sends; {
+		select {
+		case post := <-posts:
+			if post.Envelope != nil {
+				received++
+			}
+		case <-timeout:
+			t.Fatalf("received %d of %d messages", received, sends)
+		}
+	}
+}
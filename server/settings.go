@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"crypto/rsa"
+	"log/slog"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// throttledNotice tells a user their messages are dropped by the rate limit.
+const throttledNotice = "You are sending messages too fast, some of them were not delivered"
+
+// SetMOTD sets the message of the day shown to users as they log in, or
+// stops showing one if text is empty.
+func (s *Server) SetMOTD(text string) {
+	s.clientMtx.Lock()
+	s.motd = text
+	s.clientMtx.Unlock()
+}
+
+// MOTD returns the message of the day.
+func (s *Server) MOTD() string {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	return s.motd
+}
+
+// SetRateLimit limits every session to perMinute messages a minute, with
+// bursts of up to burst messages. A perMinute of 0 lifts the limit.
+func (s *Server) SetRateLimit(perMinute, burst int) {
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+
+	s.rateLimit = perMinute
+	s.rateBurst = burst
+	for _, session := range s.clients {
+		session.limiter.SetLimit(s.limit())
+		session.limiter.SetBurst(burst)
+	}
+}
+
+// limit converts the rate limit to the rate of a limiter. It must be called
+// with the client mutex held.
+func (s *Server) limit() rate.Limit {
+	if s.rateLimit <= 0 {
+		return rate.Inf
+	}
+	return rate.Every(time.Minute / time.Duration(s.rateLimit))
+}
+
+// newLimiter creates the limiter of a new session. It must be called with
+// the client mutex held.
+func (s *Server) newLimiter() *rate.Limiter {
+	return rate.NewLimiter(s.limit(), s.rateBurst)
+}
+
+// allow reports whether the session may send another message. The first
+// message dropped after one went through is reported to throttled.
+func (s *Server) allow(ctx context.Context, username string, session *Session, throttled func()) bool {
+	if session.limiter.Allow() {
+		session.throttled = false
+		return true
+	}
+
+	s.metrics.messagesThrottled.Inc()
+	if !session.throttled {
+		session.throttled = true
+		slog.InfoContext(ctx, "Throttled user", "username", username, "session", session.id)
+		throttled()
+	}
+	return false
+}
+
+// tell sends the session alone a system notice signed by the server. The
+// notice is dropped if the queue of the session is full.
+func (s *Server) tell(ctx context.Context, session *Session, text string) {
+	b, err := s.notice(ctx, PublicRoom, &chat.Message{Value: text}, s.keys.Current())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send notice", "session", session.id, "error", err)
+		return
+	}
+
+	select {
+	case session.messageBus <- b:
+	default:
+	}
+}
+
+// notice signs msg with key as a system notice for room.
+func (s *Server) notice(ctx context.Context, room string, msg *chat.Message, key *rsa.PrivateKey) (broadcast, error) {
+	notice, err := proto.Marshal(msg)
+	if err != nil {
+		return broadcast{}, err
+	}
+
+	start := time.Now()
+	signature, err := secure.Sign(key, []byte(room), notice)
+	s.metrics.timeCrypto("sign_notice", start)
+	if err != nil {
+		return broadcast{}, err
+	}
+
+	return broadcast{
+		room:      room,
+		message:   msg,
+		notice:    notice,
+		signature: signature,
+		span:      trace.SpanContextFromContext(ctx),
+	}, nil
+}
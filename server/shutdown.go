@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// restartNotice is announced to everyone when the server shuts down.
+const restartNotice = "The server is restarting, please reconnect shortly"
+
+// flushInterval is how often Shutdown checks whether the queues are empty.
+const flushInterval = 10 * time.Millisecond
+
+// Shutdown stops accepting logins, tells everyone the server is restarting
+// and waits for the messages already queued to be delivered, or for ctx to
+// be done. Join streams then end with codes.Unavailable, asking clients to
+// retry after retryAfter. Run should only be stopped once Shutdown returns.
+func (s *Server) Shutdown(ctx context.Context, retryAfter time.Duration) error {
+	s.shutdownOnce.Do(func() {
+		s.retryAfter = retryAfter
+		close(s.shutdown)
+	})
+
+	s.announce(ctx, PublicRoom, restartNotice)
+	err := s.flush(ctx)
+
+	s.closeOnce.Do(func() { close(s.closed) })
+	return err
+}
+
+// shuttingDown reports whether Shutdown was called.
+func (s *Server) shuttingDown() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// restarting returns the error refusing logins and ending streams while the
+// server shuts down, with the delay to retry after.
+func (s *Server) restarting() error {
+	st := status.New(codes.Unavailable, "server is restarting")
+	retry := &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(s.retryAfter)}
+	if detailed, err := st.WithDetails(retry); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// flush waits until the broadcaster and the queues of the sessions still
+// connected are empty.
+func (s *Server) flush(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for !s.idle() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (s *Server) idle() bool {
+	if atomic.LoadInt64(&s.pending) > 0 {
+		return false
+	}
+
+	s.clientMtx.Lock()
+	defer s.clientMtx.Unlock()
+	for _, session := range s.clients {
+		select {
+		case <-session.done:
+			continue
+		default:
+		}
+		if len(session.messageBus) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// queue hands b to the broadcaster. Messages queued once Run has returned
+// are dropped rather than left to block their sender.
+func (s *Server) queue(b broadcast) bool {
+	atomic.AddInt64(&s.pending, 1)
+	select {
+	case s.messages <- b:
+		return true
+	case <-s.stopped:
+		atomic.AddInt64(&s.pending, -1)
+		return false
+	}
+}
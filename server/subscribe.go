@@ -0,0 +1,57 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+)
+
+// Post is a message published to a room as subscribers see it: the
+// plaintext of the messages the server can read, and the envelope of those
+// encrypted end-to-end, which it cannot.
+type Post struct {
+	Message  *chat.Message
+	Envelope *chat.Envelope
+}
+
+// subscriber is a read-only listener on the fan-out of a single room. It
+// never takes part in the conversation and is not listed among the users.
+type subscriber struct {
+	room     string
+	messages chan Post
+}
+
+// Subscribe returns a read-only feed of the messages published to room from
+// now on, together with a function that cancels the subscription. A
+// subscriber that does not keep up misses messages rather than holding up
+// the conversation.
+func (s *Server) Subscribe(room string) (<-chan Post, func()) {
+	sub := &subscriber{
+		room:     room,
+		messages: make(chan Post, 100),
+	}
+
+	s.clientMtx.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.clientMtx.Unlock()
+
+	cancel := func() {
+		s.clientMtx.Lock()
+		defer s.clientMtx.Unlock()
+
+		if _, ok := s.subscribers[sub]; ok {
+			delete(s.subscribers, sub)
+			close(sub.messages)
+		}
+	}
+
+	return sub.messages, cancel
+}
+
+func (sub *subscriber) notify(post Post) {
+	select {
+	case sub.messages <- post:
+	default:
+		slog.Warn("Subscriber is not keeping up, dropping message", "room", sub.room)
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+)
+
+func TestSubscribeEncryptedMessages(t *testing.T) {
+	s := newTestServer(t)
+	posts, cancel := s.Subscribe(PublicRoom)
+	defer cancel()
+
+	c, err := client.NewClient("alice", startGRPC(t, s), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Send("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case post := <-posts:
+			if post.Envelope == nil {
+				// The notice of alice joining.
+				continue
+			}
+			if post.Message != nil {
+				t.Errorf("the server read %q", post.Message.Value)
+			}
+			if post.Envelope.Sender != "alice" || len(post.Envelope.Message) == 0 {
+				t.Errorf("got envelope %v", post.Envelope)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no envelope was forwarded to the subscriber")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// negotiation is the outcome of agreeing on a cipher suite with a client.
+type negotiation struct {
+	// offeredVersion is the protocol version of the client, and version
+	// the one chosen.
+	offeredVersion uint32
+	version        uint32
+	suite          secure.Suite
+	offered        []string
+	clientShare    []byte
+}
+
+// negotiate chooses the first suite in the client's order of preference
+// that the server allows. Clients that predate versioning are given the
+// suite of protocol version 0, if it is allowed. Clients from before session
+// keys, which log in with their RSA key alone, are not supported.
+func (s *Server) negotiate(req *chat.LoginRequest) (*negotiation, error) {
+	if req.ProtocolVersion == 0 {
+		if len(req.EphemeralKey) == 0 {
+			return nil, status.Error(codes.FailedPrecondition, "client predates session keys and is no longer supported, upgrade it")
+		}
+		suite, ok := s.allowedSuite(secure.SuiteX25519AESGCM)
+		if !ok {
+			return nil, status.Error(codes.FailedPrecondition, "client is too old for the cipher suites of the server")
+		}
+		return &negotiation{suite: suite, clientShare: req.EphemeralKey}, nil
+	}
+
+	version := req.ProtocolVersion
+	if version > secure.ProtocolVersion {
+		version = secure.ProtocolVersion
+	}
+
+	n := &negotiation{offeredVersion: req.ProtocolVersion, version: version}
+	for _, share := range req.KeyShares {
+		n.offered = append(n.offered, share.CipherSuite)
+		if n.suite != nil {
+			continue
+		}
+		if suite, ok := s.allowedSuite(share.CipherSuite); ok {
+			n.suite = suite
+			n.clientShare = share.Key
+		}
+	}
+	if n.suite == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no cipher suite in common with the client")
+	}
+	return n, nil
+}
+
+func (s *Server) allowedSuite(name string) (secure.Suite, bool) {
+	s.suitesMtx.RLock()
+	defer s.suitesMtx.RUnlock()
+
+	for _, suite := range s.suites {
+		if suite.Name() == name {
+			return suite, true
+		}
+	}
+	return nil, false
+}
+
+// transcript returns what the server signs to vouch for its share.
+func (n *negotiation) transcript(serverShare []byte) [][]byte {
+	if n.version == 0 {
+		return [][]byte{serverShare, n.clientShare}
+	}
+	return secure.LoginTranscript(n.offeredVersion, n.version, n.suite.Name(), n.offered, serverShare, n.clientShare)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/danielcopaciu/chat/secure"
+)
+
+func TestNegotiate(t *testing.T) {
+	s := newTestServer(t)
+	shares := []*chat.KeyShare{
+		{CipherSuite: "rot13", Key: []byte("key")},
+		{CipherSuite: secure.SuiteX25519ChaCha20Poly1305, Key: []byte("key")},
+	}
+
+	// A newer client is answered in the version of the server.
+	n, err := s.negotiate(&chat.LoginRequest{ProtocolVersion: secure.ProtocolVersion + 1, KeyShares: shares})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.version != secure.ProtocolVersion || n.offeredVersion != secure.ProtocolVersion+1 {
+		t.Errorf("got version %d for offered version %d", n.version, n.offeredVersion)
+	}
+	if n.suite.Name() != secure.SuiteX25519ChaCha20Poly1305 || len(n.offered) != 2 {
+		t.Errorf("chose %s out of %v", n.suite.Name(), n.offered)
+	}
+
+	n, err = s.negotiate(&chat.LoginRequest{EphemeralKey: []byte("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.version != 0 || n.suite.Name() != secure.SuiteX25519AESGCM {
+		t.Errorf("got %s in version %d for a client without versions", n.suite.Name(), n.version)
+	}
+
+	// Clients from before session keys only send their RSA key.
+	if _, err := s.negotiate(&chat.LoginRequest{ClientKey: []byte("key")}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition", err)
+	}
+	if _, err := s.negotiate(&chat.LoginRequest{ProtocolVersion: 1, KeyShares: shares[:1]}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition", err)
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/danielcopaciu/chat/server")
+
+// startSpan starts a span in the trace of the call that queued b, so that
+// a message can be followed from its sender to every session it reaches.
+func (b broadcast) startSpan(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := trace.ContextWithSpanContext(context.Background(), b.span)
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// newSessionID returns a random ID to tell apart the sessions of a user in
+// the logs.
+func newSessionID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
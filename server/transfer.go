@@ -0,0 +1,115 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/danielcopaciu/chat/generated/chat"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const chunkSize = 32 << 10
+
+func (s *Server) Upload(stream chat.Chat_UploadServer) error {
+	username, _, err := s.session(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	info := req.GetInfo()
+	if err := s.blobs.validate(info); err != nil {
+		if err == errBlobTooLarge {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id, err := s.blobs.Put(username, info, &uploadReader{stream: stream})
+	switch err {
+	case nil:
+	case errQuotaExceeded, errStorageFull:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errBlobCorrupt:
+		return status.Error(codes.DataLoss, err.Error())
+	default:
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		slog.ErrorContext(stream.Context(), "Failed to store upload", "username", username, "error", err)
+		return status.Error(codes.Internal, "failed to store file")
+	}
+
+	s.announce(stream.Context(), PublicRoom, fmt.Sprintf("%s shared %q (%d bytes) as %s", username, info.Name, info.Length, id))
+
+	return stream.SendAndClose(&chat.UploadResponse{Id: id})
+}
+
+func (s *Server) Download(req *chat.DownloadRequest, stream chat.Chat_DownloadServer) error {
+	if _, _, err := s.session(stream.Context()); err != nil {
+		return err
+	}
+
+	info, content, err := s.blobs.Get(req.Id)
+	if err != nil {
+		if err == errBlobNotFound {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		slog.ErrorContext(stream.Context(), "Failed to read file", "id", req.Id, "error", err)
+		return status.Error(codes.Internal, "failed to read file")
+	}
+	defer content.Close()
+
+	if err := stream.Send(&chat.DownloadResponse{Data: &chat.DownloadResponse_Info{Info: info}}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			chunk := &chat.DownloadResponse{Data: &chat.DownloadResponse_Chunk{Chunk: buf[:n]}}
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			slog.ErrorContext(stream.Context(), "Failed to read file", "id", req.Id, "error", err)
+			return status.Error(codes.Internal, "failed to read file")
+		}
+	}
+}
+
+// uploadReader exposes the chunks of an upload stream as an io.Reader.
+type uploadReader struct {
+	stream chat.Chat_UploadServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		chunk, ok := req.Data.(*chat.UploadRequest_Chunk)
+		if !ok {
+			return 0, status.Error(codes.InvalidArgument, "expected a file chunk")
+		}
+		r.buf = chunk.Chunk
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
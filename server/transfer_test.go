@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielcopaciu/chat/client"
+)
+
+func TestUploadEncrypted(t *testing.T) {
+	s := newTestServer(t)
+	address := startGRPC(t, s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connect := func(username string) *client.Client {
+		c, err := client.NewClient(username, address, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Connect(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	alice, bob := connect("alice"), connect("bob")
+
+	dir := t.TempDir()
+	content := []byte(strings.Repeat("the quarterly numbers ", 4096))
+	if err := ioutil.WriteFile(filepath.Join(dir, "report.txt"), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	info, ref, err := alice.Upload(ctx, filepath.Join(dir, "report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "report.txt" {
+		t.Errorf("uploaded as %q", info.Name)
+	}
+
+	id := ref[:strings.Index(ref, "#")]
+	_, stored, err := s.blobs.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(stored)
+	stored.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("quarterly")) {
+		t.Error("the server stored the file in plaintext")
+	}
+
+	path := filepath.Join(dir, "downloaded.txt")
+	got, err := bob.Download(ctx, ref, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Length != int64(len(content)) {
+		t.Errorf("downloaded %d bytes, want %d", got.Length, len(content))
+	}
+	downloaded, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded, content) {
+		t.Error("downloaded file differs from the upload")
+	}
+
+	if _, err := bob.Download(ctx, id, path); err == nil {
+		t.Error("downloaded a file without its key")
+	}
+	if _, err := bob.Download(ctx, id+"#"+strings.Repeat("A", 43), path); err == nil {
+		t.Error("downloaded a file with the wrong key")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdownSettings controls how the server drains when it stops.
+type shutdownSettings struct {
+	timeout    time.Duration
+	retryAfter time.Duration
+}
+
+func parseShutdown(timeout, retryAfter string) (shutdownSettings, error) {
+	var settings shutdownSettings
+	var err error
+	if settings.timeout, err = time.ParseDuration(timeout); err != nil {
+		return settings, errors.WithMessage(err, "invalid shutdown timeout")
+	}
+	if settings.retryAfter, err = time.ParseDuration(retryAfter); err != nil {
+		return settings, errors.WithMessage(err, "invalid retry delay")
+	}
+	return settings, nil
+}
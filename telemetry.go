@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/danielcopaciu/chat/telemetry"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const traceStdout = "stdout"
+
+// setupTelemetry logs from level up in logFormat to stderr and, unless
+// traceOutput is empty, exports spans to stdout or appends them to the file
+// traceOutput names. The returned function flushes the spans left to
+// export and closes the file, leaving stdout open.
+func setupTelemetry(logFormat string, level *slog.LevelVar, traceOutput string) (func(), error) {
+	logger, err := telemetry.NewLogger(os.Stderr, logFormat, level)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+
+	if traceOutput == "" {
+		return func() {}, nil
+	}
+
+	var w io.Writer = os.Stdout
+	closeOutput := func() error { return nil }
+	if traceOutput != traceStdout {
+		f, err := os.OpenFile(traceOutput, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to open trace output")
+		}
+		w = f
+		closeOutput = f.Close
+	}
+
+	provider := telemetry.NewTracerProvider(w, appMeta.name)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			slog.Error("Failed to export spans", "error", err)
+		}
+		if err := closeOutput(); err != nil {
+			slog.Error("Failed to close trace output", "error", err)
+		}
+	}, nil
+}
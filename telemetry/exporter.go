@@ -0,0 +1,243 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FileExporter writes spans in the OTLP JSON encoding, one export request
+// per line, as read by the otlpjsonfile receiver of the OpenTelemetry
+// collector.
+type FileExporter struct {
+	mtx sync.Mutex
+	w   io.Writer
+}
+
+func NewFileExporter(w io.Writer) *FileExporter {
+	return &FileExporter{w: w}
+}
+
+// ExportSpans writes spans as a single export request.
+func (e *FileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(exportRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// Shutdown does nothing, the writer of the exporter is closed by whoever
+// opened it.
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+type otlpRequest struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource      `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpLink struct {
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+}
+
+type otlpValues struct {
+	Values []otlpValue `json:"values"`
+}
+
+// exportRequest groups spans by resource and instrumentation scope.
+func exportRequest(spans []sdktrace.ReadOnlySpan) *otlpRequest {
+	request := &otlpRequest{}
+	resources := make(map[*resource.Resource]*otlpResourceSpans)
+	scopes := make(map[*resource.Resource]map[instrumentation.Scope]*otlpScopeSpans)
+
+	for _, span := range spans {
+		res := span.Resource()
+		resourceSpans, ok := resources[res]
+		if !ok {
+			resourceSpans = &otlpResourceSpans{Resource: otlpResource{Attributes: keyValues(res.Attributes())}}
+			resources[res] = resourceSpans
+			scopes[res] = make(map[instrumentation.Scope]*otlpScopeSpans)
+			request.ResourceSpans = append(request.ResourceSpans, resourceSpans)
+		}
+
+		scope := span.InstrumentationScope()
+		scopeSpans, ok := scopes[res][scope]
+		if !ok {
+			scopeSpans = &otlpScopeSpans{Scope: otlpScope{Name: scope.Name, Version: scope.Version}}
+			scopes[res][scope] = scopeSpans
+			resourceSpans.ScopeSpans = append(resourceSpans.ScopeSpans, scopeSpans)
+		}
+
+		scopeSpans.Spans = append(scopeSpans.Spans, encodeSpan(span))
+	}
+	return request
+}
+
+func encodeSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	encoded := otlpSpan{
+		TraceID:           span.SpanContext().TraceID().String(),
+		SpanID:            span.SpanContext().SpanID().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+		Attributes:        keyValues(span.Attributes()),
+		Status:            encodeStatus(span.Status()),
+	}
+	if parent := span.Parent(); parent.IsValid() {
+		encoded.ParentSpanID = parent.SpanID().String()
+	}
+	for _, event := range span.Events() {
+		encoded.Events = append(encoded.Events, otlpEvent{
+			TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+			Name:         event.Name,
+			Attributes:   keyValues(event.Attributes),
+		})
+	}
+	for _, link := range span.Links() {
+		encoded.Links = append(encoded.Links, otlpLink{
+			TraceID:    link.SpanContext.TraceID().String(),
+			SpanID:     link.SpanContext.SpanID().String(),
+			Attributes: keyValues(link.Attributes),
+		})
+	}
+	return encoded
+}
+
+// encodeStatus maps the status codes of the SDK, which differ from those of
+// OTLP.
+func encodeStatus(status sdktrace.Status) otlpStatus {
+	switch status.Code {
+	case codes.Ok:
+		return otlpStatus{Code: 1}
+	case codes.Error:
+		return otlpStatus{Code: 2, Message: status.Description}
+	default:
+		return otlpStatus{}
+	}
+}
+
+func keyValues(attrs []attribute.KeyValue) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		encoded = append(encoded, otlpKeyValue{Key: string(attr.Key), Value: encodeValue(attr.Value)})
+	}
+	return encoded
+}
+
+func encodeValue(value attribute.Value) otlpValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		v := value.AsBool()
+		return otlpValue{BoolValue: &v}
+	case attribute.INT64:
+		v := strconv.FormatInt(value.AsInt64(), 10)
+		return otlpValue{IntValue: &v}
+	case attribute.FLOAT64:
+		v := value.AsFloat64()
+		return otlpValue{DoubleValue: &v}
+	case attribute.BOOLSLICE:
+		var values []otlpValue
+		for _, v := range value.AsBoolSlice() {
+			values = append(values, encodeValue(attribute.BoolValue(v)))
+		}
+		return otlpValue{ArrayValue: &otlpValues{Values: values}}
+	case attribute.INT64SLICE:
+		var values []otlpValue
+		for _, v := range value.AsInt64Slice() {
+			values = append(values, encodeValue(attribute.Int64Value(v)))
+		}
+		return otlpValue{ArrayValue: &otlpValues{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []otlpValue
+		for _, v := range value.AsFloat64Slice() {
+			values = append(values, encodeValue(attribute.Float64Value(v)))
+		}
+		return otlpValue{ArrayValue: &otlpValues{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []otlpValue
+		for _, v := range value.AsStringSlice() {
+			values = append(values, encodeValue(attribute.StringValue(v)))
+		}
+		return otlpValue{ArrayValue: &otlpValues{Values: values}}
+	default:
+		v := value.Emit()
+		return otlpValue{StringValue: &v}
+	}
+}
@@ -0,0 +1,150 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "github.com/danielcopaciu/chat/telemetry"
+
+var tracer = otel.Tracer(tracerName)
+
+type fieldsKey struct{}
+
+// fields collects what the handler of a call learns about it, such as the
+// user it was made for, to log once the call returns.
+type fields struct {
+	mtx   sync.Mutex
+	attrs []slog.Attr
+}
+
+// AddFields attaches attrs to the span of the call ctx belongs to, and to
+// the record logged when it returns.
+func AddFields(ctx context.Context, attrs ...slog.Attr) {
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.mtx.Lock()
+		f.attrs = append(f.attrs, attrs...)
+		f.mtx.Unlock()
+	}
+
+	span := trace.SpanFromContext(ctx)
+	for _, attr := range attrs {
+		span.SetAttributes(attribute.String(attr.Key, attr.Value.String()))
+	}
+}
+
+// UnaryServerInterceptor traces unary calls and logs each one once it
+// returns.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, finish := startCall(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	finish(err)
+	return resp, err
+}
+
+// StreamServerInterceptor traces streams and logs each one once it ends.
+func StreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, finish := startCall(stream.Context(), info.FullMethod)
+	err := handler(srv, &tracedStream{ServerStream: stream, ctx: ctx})
+	finish(err)
+	return err
+}
+
+// tracedStream hands the context of the call span to the stream handler.
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// startCall starts the span of a call, continuing the trace of the client
+// if it sent one, and returns the function that ends and logs it.
+func startCall(ctx context.Context, method string) (context.Context, func(error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+
+	f := &fields{}
+	ctx = context.WithValue(ctx, fieldsKey{}, f)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		duration := time.Since(start)
+		code := status.Code(err)
+
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+		if err != nil {
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		span.End()
+
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("code", code.String()),
+			slog.Duration("duration", duration),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("peer", p.Addr.String()))
+		}
+		f.mtx.Lock()
+		attrs = append(attrs, f.attrs...)
+		f.mtx.Unlock()
+
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK, codes.Canceled:
+		case codes.Unknown, codes.Internal, codes.DataLoss:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		slog.LogAttrs(ctx, level, "Handled call", attrs...)
+	}
+}
+
+// metadataCarrier reads the trace context propagated in gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c)[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c)[key] = []string{value}
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,72 @@
+// Package telemetry traces and logs the calls the server handles, so that a
+// message can be followed from the client that sent it to every session it
+// is delivered to.
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Log formats accepted by NewLogger.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// NewLogger returns a logger writing records from level up to w in format.
+// Records logged with the context of a traced call carry its trace and span
+// IDs.
+func NewLogger(w io.Writer, format string, level slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, errors.Errorf("unknown log format %q, expected %s or %s", format, FormatJSON, FormatText)
+	}
+	return slog.New(traceHandler{handler}), nil
+}
+
+// NewTracerProvider returns a tracer provider that exports the spans of
+// service to w in the OTLP JSON encoding.
+func NewTracerProvider(w io.Writer, service string) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(NewFileExporter(w)),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+}
+
+// traceHandler adds the trace and span IDs of the context to records.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
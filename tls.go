@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// loadCertPool reads the PEM encoded CA certificates in path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// clientTLSConfig returns the TLS configuration of a client trusting the
+// CAs in caFile, or the system roots if it is empty, and presenting the
+// given certificate, along with the username the server knows it by: the
+// common name of the certificate.
+func clientTLSConfig(certFile, keyFile, caFile, serverName string) (*tls.Config, string, error) {
+	config := &tls.Config{ServerName: serverName}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, "", err
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile == "" && keyFile == "" {
+		return config, "", nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed to load client certificate")
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed to load client certificate")
+	}
+	if leaf.Subject.CommonName == "" {
+		return nil, "", errors.New("client certificate has no common name to use as username")
+	}
+
+	config.Certificates = []tls.Certificate{cert}
+	return config, leaf.Subject.CommonName, nil
+}
+
+// certReloader serves a certificate from files, loading it again whenever
+// the files change so that it can be renewed without a restart.
+type certReloader struct {
+	certFile, keyFile string
+
+	mtx      sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	if keyFile == "" {
+		return nil, errors.New("a key is required with the TLS certificate")
+	}
+
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if
+// either file changed. A certificate that fails to load is logged and the
+// previous one kept.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if modified, err := r.lastModified(); err == nil && !modified.Equal(r.modified) {
+		if err := r.reload(); err != nil {
+			slog.Error("Failed to reload TLS certificate", "path", r.certFile, "error", err)
+		} else {
+			slog.Info("Reloaded TLS certificate", "path", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) reload() error {
+	modified, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load TLS certificate")
+	}
+
+	r.cert = &cert
+	r.modified = modified
+	return nil
+}
+
+// lastModified returns the latest modification time of the two files.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/danielcopaciu/chat/identity"
+	"github.com/danielcopaciu/chat/secure"
+	"github.com/pkg/errors"
+)
+
+func loadKnownServers(path string) (*client.KnownServers, error) {
+	path, err := configPath(path, "known_servers")
+	if err != nil {
+		return nil, err
+	}
+	return client.NewKnownServers(path), nil
+}
+
+func loadVerifiedUsers(path string) (*client.VerifiedUsers, error) {
+	path, err := configPath(path, "verified_users")
+	if err != nil {
+		return nil, err
+	}
+	return client.NewVerifiedUsers(path), nil
+}
+
+// configPath returns path, or the file name in the user config directory
+// when it is empty.
+func configPath(path, name string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	dir, err := identity.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// runTrust pins the current key of the server at serverAddress, once the
+// user confirms its fingerprint or it matches the expected one.
+func runTrust(ctx context.Context, serverAddress string, insecure bool, tlsConfig *tls.Config, knownServers *client.KnownServers, expected string) error {
+	fetchCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	serverKey, err := client.FetchServerKey(fetchCtx, serverAddress, insecure, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := secure.KeyFingerprint(serverKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The key of %s has the fingerprint\n  %s\n", serverAddress, fingerprint)
+	if expected != "" {
+		if strings.Join(strings.Fields(expected), " ") != fingerprint {
+			return errors.New("the fingerprint does not match, the key was not trusted")
+		}
+	} else {
+		fmt.Print("Compare it with the one the server operator gave you. Trust this key? [y/N] ")
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer != "y" && answer != "yes" {
+			return errors.New("the key was not trusted")
+		}
+	}
+
+	if err := knownServers.Trust(serverAddress, fingerprint); err != nil {
+		return err
+	}
+	fmt.Printf("Trusted the key of %s\n", serverAddress)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/danielcopaciu/chat/tui"
+)
+
+// runTUI connects c and shows the conversation full screen until the user
+// quits, or SIGTERM or SIGHUP arrives. The terminal being in raw mode,
+// Ctrl-C is a key of the screen rather than a signal.
+func runTUI(c *client.Client) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := c.Connect(ctx); err != nil {
+		return err
+	}
+	defer c.Close()
+
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(exit)
+	go func() {
+		select {
+		case <-exit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return tui.Run(ctx, c)
+}
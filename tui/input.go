@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// maxHistory is the number of lines typed kept in the history.
+const maxHistory = 500
+
+// history holds the lines typed, to bring them back with the arrow keys.
+type history struct {
+	lines []string
+	// position is the index of the line shown, or len(lines) while the
+	// draft being typed is.
+	position int
+	draft    string
+}
+
+// add appends line to the history, unless it repeats the last one, and goes
+// back to a new draft.
+func (h *history) add(line string) {
+	if n := len(h.lines); n == 0 || h.lines[n-1] != line {
+		h.lines = append(h.lines, line)
+	}
+	if len(h.lines) > maxHistory {
+		h.lines = h.lines[len(h.lines)-maxHistory:]
+	}
+	h.position = len(h.lines)
+	h.draft = ""
+}
+
+// previous returns the line before the one shown, keeping current as the
+// draft when leaving it.
+func (h *history) previous(current string) (string, bool) {
+	if h.position == 0 {
+		return "", false
+	}
+	if h.position == len(h.lines) {
+		h.draft = current
+	}
+	h.position--
+	return h.lines[h.position], true
+}
+
+// next returns the line after the one shown, or the draft after the last
+// one.
+func (h *history) next() (string, bool) {
+	if h.position == len(h.lines) {
+		return "", false
+	}
+	h.position++
+	if h.position == len(h.lines) {
+		return h.draft, true
+	}
+	return h.lines[h.position], true
+}
+
+// complete completes the last word of line to the username it is the start
+// of, if only one is. A username completed at the start of the line is
+// followed by a colon, as when addressing the user.
+func complete(line string, users []string) string {
+	start := strings.LastIndex(line, " ") + 1
+	word := strings.ToLower(line[start:])
+	if word == "" {
+		return line
+	}
+
+	var match string
+	for _, user := range users {
+		if strings.HasPrefix(strings.ToLower(user), word) {
+			if match != "" {
+				return line
+			}
+			match = user
+		}
+	}
+	if match == "" {
+		return line
+	}
+
+	if start == 0 {
+		return match + ": "
+	}
+	return line[:start] + match + " "
+}
+
+// palette holds the colors users are told apart by.
+var palette = []string{
+	"red", "green", "yellow", "dodgerblue", "fuchsia", "aqua",
+	"orange", "lime", "violet", "turquoise", "salmon", "gold",
+}
+
+// userColor picks the color of username, the same on every screen.
+func userColor(username string) string {
+	h := fnv.New32a()
+	h.Write([]byte(username))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// splitSender splits the sender of a message into the username and the
+// notes the client appended to it, e.g. " (unverified)".
+func splitSender(sender string) (string, string) {
+	if i := strings.Index(sender, " ("); i >= 0 {
+		return sender[:i], sender[i:]
+	}
+	return sender, ""
+}
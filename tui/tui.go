@@ -0,0 +1,475 @@
+// Package tui shows the conversation of a client full screen, next to the
+// users taking part in it, with the line being typed kept apart from the
+// messages arriving.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/danielcopaciu/chat/generated/chat"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// maxLines is the number of lines kept in the conversation before the
+	// oldest are dropped.
+	maxLines = 5000
+
+	// usersInterval is how often the users are refreshed besides when a
+	// notice arrives.
+	usersInterval = 30 * time.Second
+
+	sidebarWidth = 24
+
+	// divider marks where the unread lines start.
+	divider = "[red]──── new messages ────[-]"
+
+	placeholder = "Type a message, or /upload, /download, /verify, /clear, /logout. PgUp/PgDn scroll, Esc jumps to the end"
+)
+
+// ui is the screen of a client. Apart from the queues, its fields are only
+// used on the goroutine of the application.
+type ui struct {
+	client   *client.Client
+	app      *tview.Application
+	messages *tview.TextView
+	sidebar  *tview.TextView
+	input    *tview.InputField
+
+	// typed queues the lines typed for the client to execute, and refresh
+	// asks for the users to be fetched again.
+	typed   chan string
+	refresh chan struct{}
+
+	// lines holds the conversation, in which divider is the index of the
+	// first line that arrived while scrolled back, or -1.
+	lines   []string
+	divider int
+
+	// following is set while the end of the conversation is in view. The
+	// lines and the messages of each user that arrive otherwise are
+	// counted as unread.
+	following bool
+	unread    int
+	unreadBy  map[string]int
+
+	users   []string
+	history history
+
+	// ended is set once the conversation is over, and err is why.
+	ended bool
+	err   error
+}
+
+// Run shows the conversation of c, which must be connected, until the user
+// logs out or quits with Ctrl-C, ctx is done or the server ends the
+// conversation. The user is logged out unless the server ended it. Logs are
+// shown in the conversation meanwhile.
+func Run(ctx context.Context, c *client.Client) error {
+	u := newUI(c)
+
+	output := log.Writer()
+	log.SetOutput(noticeWriter{u})
+	defer log.SetOutput(output)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go u.receive()
+	go u.execute(ctx)
+	go u.watchUsers(ctx)
+	go func() {
+		<-ctx.Done()
+		u.app.QueueUpdate(u.app.Stop)
+	}()
+
+	if err := u.app.Run(); err != nil {
+		return err
+	}
+	if !u.ended {
+		return c.Logout()
+	}
+	return u.err
+}
+
+func newUI(c *client.Client) *ui {
+	u := &ui{
+		client:    c,
+		app:       tview.NewApplication(),
+		messages:  tview.NewTextView(),
+		sidebar:   tview.NewTextView(),
+		input:     tview.NewInputField(),
+		typed:     make(chan string, 16),
+		refresh:   make(chan struct{}, 1),
+		divider:   -1,
+		following: true,
+		unreadBy:  make(map[string]int),
+	}
+
+	u.messages.
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetWordWrap(true).
+		SetBorder(true)
+	u.sidebar.
+		SetDynamicColors(true).
+		SetBorder(true)
+	u.input.
+		SetLabel(fmt.Sprintf("[%s::b]%s[-::-]> ", userColor(c.Username()), tview.Escape(c.Username()))).
+		SetPlaceholder(placeholder).
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetPlaceholderStyle(tcell.StyleDefault.Foreground(tcell.ColorGray)).
+		SetDoneFunc(u.done).
+		SetInputCapture(u.key)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(u.messages, 0, 1, false).
+			AddItem(u.sidebar, sidebarWidth, 0, false), 0, 1, false).
+		AddItem(u.input, 1, 0, true)
+
+	u.app.
+		SetRoot(layout, true).
+		SetFocus(u.input).
+		EnableMouse(true).
+		SetMouseCapture(u.mouse)
+
+	u.updateTitle()
+	u.drawSidebar()
+	return u
+}
+
+// receive shows the messages of the conversation as they arrive, until it
+// ends.
+func (u *ui) receive() {
+	for {
+		msg, err := u.client.Receive()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			u.app.QueueUpdate(func() { u.end(err) })
+			return
+		}
+
+		u.app.QueueUpdateDraw(func() { u.show(msg) })
+		if msg.Sender == "" {
+			u.refreshUsers()
+		}
+	}
+}
+
+// execute hands the lines typed to the client one at a time, reporting in
+// the conversation, until ctx is done or the user logs out.
+func (u *ui) execute(ctx context.Context) {
+	out := noticeWriter{u}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line := <-u.typed:
+			err := u.client.Execute(line, out)
+			switch {
+			case err == client.ErrLoggedOut:
+				u.app.QueueUpdate(func() { u.end(nil) })
+				return
+			case err != nil:
+				fmt.Fprintf(out, "send failed: %v\n", err)
+			}
+		}
+	}
+}
+
+// watchUsers fetches the users when asked to and every usersInterval, until
+// ctx is done.
+func (u *ui) watchUsers(ctx context.Context) {
+	ticker := time.NewTicker(usersInterval)
+	defer ticker.Stop()
+
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		users, err := u.client.Users(reqCtx)
+		cancel()
+		if err == nil {
+			u.app.QueueUpdateDraw(func() {
+				u.users = users
+				u.drawSidebar()
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-u.refresh:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *ui) refreshUsers() {
+	select {
+	case u.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// end stops the application once the conversation is over.
+func (u *ui) end(err error) {
+	u.ended = true
+	u.err = err
+	u.app.Stop()
+}
+
+// show adds a message to the conversation, with its sender in their color
+// followed by what the client noted about them.
+func (u *ui) show(msg *chat.Message) {
+	if msg.Sender == "" {
+		u.notice(msg.Value)
+		return
+	}
+
+	name, note := splitSender(msg.Sender)
+	u.add(name, fmt.Sprintf("[gray]%s[-] [%s::b]%s[-::-][gray]%s[-]: %s",
+		time.Now().Format("15:04"), userColor(name), tview.Escape(name), tview.Escape(note), tview.Escape(msg.Value)))
+}
+
+// notice adds what the server or the client tells the user to the
+// conversation.
+func (u *ui) notice(text string) {
+	u.add("", fmt.Sprintf("[gray]%s -- %s[-]", time.Now().Format("15:04"), tview.Escape(text)))
+}
+
+// add appends a line from sender to the conversation, counting it as unread
+// when the end of the conversation is out of view.
+func (u *ui) add(sender, line string) {
+	if !u.following {
+		if u.unread == 0 {
+			u.moveDivider()
+		}
+		u.unread++
+		if sender != "" {
+			u.unreadBy[sender]++
+		}
+		u.updateTitle()
+		u.drawSidebar()
+	}
+
+	if len(u.lines) >= maxLines+maxLines/10 {
+		drop := len(u.lines) - maxLines + 1
+		u.lines = append(u.lines[drop:], line)
+		if u.divider -= drop; u.divider < 0 {
+			u.divider = -1
+		}
+		u.render()
+		return
+	}
+	u.write(line)
+	u.lines = append(u.lines, line)
+}
+
+// moveDivider marks the end of the conversation as where the unread lines
+// start, removing the mark left when the user last scrolled back.
+func (u *ui) moveDivider() {
+	if u.divider >= 0 {
+		u.divider = len(u.lines)
+		u.render()
+		return
+	}
+	u.write(divider)
+	u.divider = len(u.lines)
+}
+
+// write appends text to the conversation on a line of its own, without
+// leaving an empty line at the end.
+func (u *ui) write(text string) {
+	if len(u.lines) > 0 || u.divider >= 0 {
+		text = "\n" + text
+	}
+	fmt.Fprint(u.messages, text)
+}
+
+// render writes the whole conversation again, with the divider marking the
+// first unread line.
+func (u *ui) render() {
+	row, _ := u.messages.GetScrollOffset()
+
+	lines := make([]string, 0, len(u.lines)+1)
+	for i, line := range u.lines {
+		if i == u.divider {
+			lines = append(lines, divider)
+		}
+		lines = append(lines, line)
+	}
+	if u.divider == len(u.lines) {
+		lines = append(lines, divider)
+	}
+	u.messages.SetText(strings.Join(lines, "\n"))
+
+	if u.following {
+		u.messages.ScrollToEnd()
+	} else {
+		u.messages.ScrollTo(row, 0)
+	}
+}
+
+func (u *ui) clear() {
+	u.lines = nil
+	u.divider = -1
+	u.messages.Clear()
+	u.follow()
+}
+
+// scroll moves the conversation by rows, following it again once its end
+// comes into view.
+func (u *ui) scroll(rows int) {
+	row, _ := u.messages.GetScrollOffset()
+	_, _, _, height := u.messages.GetInnerRect()
+	if row+rows+height >= u.messages.GetWrappedLineCount() {
+		u.follow()
+		return
+	}
+
+	if row += rows; row < 0 {
+		row = 0
+	}
+	u.following = false
+	u.messages.ScrollTo(row, 0)
+}
+
+// follow scrolls to the end of the conversation, which marks it read. The
+// divider stays until the user scrolls back again.
+func (u *ui) follow() {
+	u.following = true
+	u.unread = 0
+	u.unreadBy = make(map[string]int)
+	u.messages.ScrollToEnd()
+	u.updateTitle()
+	u.drawSidebar()
+}
+
+func (u *ui) updateTitle() {
+	title := " public "
+	if u.unread > 0 {
+		title = fmt.Sprintf(" public [red](%d new)[-] ", u.unread)
+	}
+	u.messages.SetTitle(title)
+}
+
+// drawSidebar lists the room and the users, with the number of messages
+// each sent since the user scrolled back.
+func (u *ui) drawSidebar() {
+	var text strings.Builder
+	text.WriteString("[::b]Rooms[::-]\n")
+	text.WriteString(" # public")
+	if u.unread > 0 {
+		fmt.Fprintf(&text, " [red](%d)[-]", u.unread)
+	}
+
+	fmt.Fprintf(&text, "\n\n[::b]Users (%d)[::-]\n", len(u.users))
+	for _, user := range u.users {
+		fmt.Fprintf(&text, " [%s]●[-] %s", userColor(user), tview.Escape(user))
+		if user == u.client.Username() {
+			text.WriteString(" [gray](you)[-]")
+		}
+		if n := u.unreadBy[user]; n > 0 {
+			fmt.Fprintf(&text, " [red](%d)[-]", n)
+		}
+		text.WriteByte('\n')
+	}
+	u.sidebar.SetText(text.String())
+}
+
+// done hands the line typed to the client when Enter is pressed, apart from
+// /clear which empties the conversation.
+func (u *ui) done(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		return
+	}
+
+	line := u.input.GetText()
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	u.history.add(line)
+	u.input.SetText("")
+
+	if line == "/clear" {
+		u.clear()
+		return
+	}
+
+	u.follow()
+	select {
+	case u.typed <- line:
+	default:
+		u.notice("still busy with the previous lines, try again")
+	}
+}
+
+// key handles the keys of the input line that do not edit it: the arrows
+// browse the history, PgUp and PgDn scroll the conversation, Esc jumps to
+// its end and Tab completes usernames.
+func (u *ui) key(event *tcell.EventKey) *tcell.EventKey {
+	_, _, _, height := u.messages.GetInnerRect()
+	switch event.Key() {
+	case tcell.KeyUp:
+		if line, ok := u.history.previous(u.input.GetText()); ok {
+			u.input.SetText(line)
+		}
+	case tcell.KeyDown:
+		if line, ok := u.history.next(); ok {
+			u.input.SetText(line)
+		}
+	case tcell.KeyPgUp:
+		u.scroll(-height + 1)
+	case tcell.KeyPgDn:
+		u.scroll(height - 1)
+	case tcell.KeyEscape:
+		u.follow()
+	case tcell.KeyTab:
+		u.input.SetText(complete(u.input.GetText(), u.users))
+	default:
+		return event
+	}
+	return nil
+}
+
+// mouse scrolls the conversation with the wheel.
+func (u *ui) mouse(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+	if !u.messages.InRect(event.Position()) {
+		return event, action
+	}
+
+	switch action {
+	case tview.MouseScrollUp:
+		u.scroll(-3)
+	case tview.MouseScrollDown:
+		u.scroll(3)
+	default:
+		return event, action
+	}
+	return nil, 0
+}
+
+// noticeWriter shows what is written to it as notices in the conversation.
+type noticeWriter struct {
+	ui *ui
+}
+
+func (w noticeWriter) Write(p []byte) (int, error) {
+	text := strings.TrimRight(string(p), "\n")
+	w.ui.app.QueueUpdateDraw(func() {
+		for _, line := range strings.Split(text, "\n") {
+			w.ui.notice(line)
+		}
+	})
+	return len(p), nil
+}
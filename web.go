@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+func startHTTPServer(name, address string, handler http.Handler) (func(), error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	httpServer := &http.Server{Handler: handler}
+
+	slog.Info("Starting HTTP server", "server", name, "address", address)
+	go httpServer.Serve(lis)
+
+	return func() {
+		slog.Info("Stopping HTTP server", "server", name)
+		httpServer.Close()
+	}, nil
+}
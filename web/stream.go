@@ -0,0 +1,139 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danielcopaciu/chat/server"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+// Subscriber provides read-only feeds of the conversation.
+type Subscriber interface {
+	Subscribe(room string) (<-chan server.Post, func())
+}
+
+// Stream serves the conversation of a room as server-sent events to anyone
+// holding the read token, e.g.
+//
+//	curl -N -H 'Authorization: Bearer <token>' http://host/stream?room=ops
+//
+// The public conversation is streamed when no room is given. Messages the
+// server can read are sent as message events, and those encrypted
+// end-to-end as envelope events carrying their ciphertext.
+type Stream struct {
+	subscriber Subscriber
+	token      string
+}
+
+type streamEvent struct {
+	Room   string    `json:"room,omitempty"`
+	Sender string    `json:"sender,omitempty"`
+	Value  string    `json:"value"`
+	Time   time.Time `json:"time"`
+}
+
+type envelopeEvent struct {
+	Room       string    `json:"room,omitempty"`
+	Sender     string    `json:"sender"`
+	Recipients []string  `json:"recipients"`
+	Ciphertext []byte    `json:"ciphertext"`
+	Time       time.Time `json:"time"`
+}
+
+func NewStream(subscriber Subscriber, token string) *Stream {
+	return &Stream{
+		subscriber: subscriber,
+		token:      token,
+	}
+}
+
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid read token", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	room := r.URL.Query().Get("room")
+	messages, cancel := s.subscriber.Subscribe(room)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case post, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			name, event := postEvent(room, post)
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// postEvent returns the name and the data of the event of post.
+func postEvent(room string, post server.Post) (string, interface{}) {
+	now := time.Now().UTC()
+	if post.Message != nil {
+		return "message", streamEvent{
+			Room:   room,
+			Sender: post.Message.Sender,
+			Value:  post.Message.Value,
+			Time:   now,
+		}
+	}
+
+	recipients := make([]string, 0, len(post.Envelope.Keys))
+	for recipient := range post.Envelope.Keys {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+	return "envelope", envelopeEvent{
+		Room:       room,
+		Sender:     post.Envelope.Sender,
+		Recipients: recipients,
+		Ciphertext: post.Envelope.Message,
+		Time:       now,
+	}
+}
+
+// authorized accepts the read token either as a bearer token or, for
+// clients such as EventSource that cannot set headers, as a query parameter.
+func (s *Stream) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
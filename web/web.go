@@ -0,0 +1,311 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielcopaciu/chat/client"
+	"github.com/pkg/errors"
+)
+
+//go:embed static
+var static embed.FS
+
+// Bridge serves the web chat UI and relays each browser session to the chat
+// server through its own client. Messages flow to the browser as server-sent
+// events and back through plain JSON posts.
+type Bridge struct {
+	serverAddress string
+	insecure      bool
+	tlsConfig     *tls.Config
+	sessions      map[string]*session
+	sessionMtx    sync.Mutex
+}
+
+type session struct {
+	client  *client.Client
+	cancel  context.CancelFunc
+	events  chan event
+	sendMtx sync.Mutex
+}
+
+type event struct {
+	Sender string `json:"sender,omitempty"`
+	Value  string `json:"value"`
+}
+
+// NewBridge returns a bridge connecting to the chat server at
+// serverAddress, verifying its certificate with tlsConfig unless insecure.
+func NewBridge(serverAddress string, insecure bool, tlsConfig *tls.Config) *Bridge {
+	return &Bridge{
+		serverAddress: serverAddress,
+		insecure:      insecure,
+		tlsConfig:     tlsConfig,
+		sessions:      make(map[string]*session),
+	}
+}
+
+func (b *Bridge) Handler() http.Handler {
+	content, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(content)))
+	mux.HandleFunc("/api/login", b.login)
+	mux.HandleFunc("/api/logout", b.logout)
+	mux.HandleFunc("/api/messages", b.messages)
+	mux.HandleFunc("/api/users", b.users)
+	mux.HandleFunc("/api/events", b.events)
+	return mux
+}
+
+// Close logs out every browser session still attached to the bridge.
+func (b *Bridge) Close() {
+	b.sessionMtx.Lock()
+	defer b.sessionMtx.Unlock()
+
+	for token, session := range b.sessions {
+		session.close()
+		delete(b.sessions, token)
+	}
+}
+
+func (b *Bridge) login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid login request", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		http.Error(w, "username is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := b.connect(username)
+	if err != nil {
+		slog.Warn("Failed to log in web user", "username", username, "error", err)
+		http.Error(w, "failed to join the conversation", http.StatusBadGateway)
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		session.close()
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	b.sessionMtx.Lock()
+	b.sessions[token] = session
+	b.sessionMtx.Unlock()
+
+	go b.relay(token, session)
+
+	writeJSON(w, map[string]string{"token": token})
+}
+
+func (b *Bridge) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	b.sessionMtx.Lock()
+	session, ok := b.sessions[token]
+	delete(b.sessions, token)
+	b.sessionMtx.Unlock()
+
+	if ok {
+		session.close()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Bridge) messages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := b.session(r)
+	if session == nil {
+		http.Error(w, "unknown session", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+
+	session.sendMtx.Lock()
+	err := session.client.Send(req.Value)
+	session.sendMtx.Unlock()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to send message: %v", err), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Bridge) users(w http.ResponseWriter, r *http.Request) {
+	session := b.session(r)
+	if session == nil {
+		http.Error(w, "unknown session", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	users, err := session.client.Users(ctx)
+	if err != nil {
+		http.Error(w, "failed to list users", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, map[string][]string{"users": users})
+}
+
+func (b *Bridge) events(w http.ResponseWriter, r *http.Request) {
+	session := b.session(r)
+	if session == nil {
+		http.Error(w, "unknown session", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-session.events:
+			if !ok {
+				fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (b *Bridge) connect(username string) (*session, error) {
+	c, err := client.NewClient(username, b.serverAddress, b.insecure, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.SetTLSConfig(b.tlsConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.Connect(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &session{
+		client: c,
+		cancel: cancel,
+		events: make(chan event, 100),
+	}, nil
+}
+
+// relay forwards messages received by the session client to its event
+// queue until the conversation ends. Events are dropped rather than
+// stalling the client when the browser is not reading them.
+func (b *Bridge) relay(token string, session *session) {
+	defer close(session.events)
+	for {
+		msg, err := session.client.Receive()
+		if err != nil {
+			if err != io.EOF {
+				slog.Warn("Web session closed", "username", session.client.Username(), "error", err)
+			}
+
+			b.sessionMtx.Lock()
+			delete(b.sessions, token)
+			b.sessionMtx.Unlock()
+
+			session.client.Close()
+			session.cancel()
+			return
+		}
+
+		select {
+		case session.events <- event{Sender: msg.Sender, Value: msg.Value}:
+		default:
+			slog.Warn("Web session is not keeping up, dropping message", "username", session.client.Username())
+		}
+	}
+}
+
+func (b *Bridge) session(r *http.Request) *session {
+	b.sessionMtx.Lock()
+	defer b.sessionMtx.Unlock()
+
+	return b.sessions[r.URL.Query().Get("token")]
+}
+
+func (s *session) close() {
+	if err := s.client.Logout(); err != nil {
+		slog.Warn("Failed to log out web user", "username", s.client.Username(), "error", err)
+	}
+	s.client.Close()
+	s.cancel()
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.WithMessage(err, "failed to generate token")
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write web response", "error", err)
+	}
+}